@@ -0,0 +1,130 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceFeatureEvaluation() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFeatureEvaluationRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+			},
+			"user": {
+				Type:        schema.TypeString,
+				Description: "User the Microsoft.Targeting filters are evaluated for",
+				Optional:    true,
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Description: "Groups of the user",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"timestamp": {
+				Type:         schema.TypeString,
+				Description:  "RFC3339 timestamp the Microsoft.TimeWindow filters are evaluated at, now by default",
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the feature is on for the user, its groups and the timestamp",
+				Computed:    true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceFeatureEvaluationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	label := d.Get("label").(string)
+	name := d.Get("name").(string)
+
+	evaluation := client.EvaluationContext{
+		Targeting: client.TargetingContext{
+			UserID: d.Get("user").(string),
+			Groups: expandStrings(d.Get("groups").([]interface{})),
+		},
+	}
+
+	timestamp, err := parseOptionalTime(d.Get("timestamp").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	evaluation.Time = timestamp
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var feature client.FeatureResponse
+	err = resource.RetryContext(ctx, readTimeout, func() *resource.RetryError {
+		feature, err = cl.GetFeatureContext(ctx, label, name)
+
+		if err != nil {
+			if client.IsNotFound(err) {
+				log.Printf("[INFO] retrying to get feature '%s:%s' because: %s", label, name, err)
+
+				return resource.RetryableError(err)
+			}
+
+			return resource.NonRetryableError(err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting App Configuration feature %s/%s", label, name), err)
+	}
+
+	// the Microsoft.Percentage filter draws at random, the data source would change at every refresh
+	for _, filter := range feature.Conditions.ClientFilters {
+		if feature.Enabled && filter.Name == client.PercentageFilterName {
+			return errorDiagnostics(fmt.Sprintf("error evaluating App Configuration feature %s/%s", label, name),
+				fmt.Errorf("the %s filter is random, it cannot be evaluated by a data source", client.PercentageFilterName))
+		}
+	}
+
+	enabled, err := feature.Evaluate(name, evaluation)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error evaluating App Configuration feature %s/%s", label, name), err)
+	}
+
+	id, err := formatFeatureID(endpoint, label, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("enabled", enabled)
+
+	log.Printf("[INFO] Feature %s/%s/%s evaluated to %t", endpoint, label, name, enabled)
+
+	return nil
+}
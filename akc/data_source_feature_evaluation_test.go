@@ -0,0 +1,112 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccDataSourceFeatureEvaluation_targetingAndTimeWindow(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:  func() { preCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: buildFeatureEvaluations(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.akc_feature_evaluation.alice", "enabled", "true"),
+					resource.TestCheckResourceAttr("data.akc_feature_evaluation.beta", "enabled", "true"),
+					resource.TestCheckResourceAttr("data.akc_feature_evaluation.bob", "enabled", "false"),
+					resource.TestCheckResourceAttr("data.akc_feature_evaluation.too_early", "enabled", "false"),
+				),
+			},
+		},
+	})
+}
+
+func buildFeatureEvaluations(name string) string {
+	return fmt.Sprintf(`
+resource "akc_feature" "test" {
+  endpoint         = "%s"
+  name             = "%s"
+  enabled          = true
+  requirement_type = "All"
+
+  client_filter {
+    targeting {
+      users = ["alice"]
+      group {
+        name               = "beta"
+        rollout_percentage = 100
+      }
+    }
+  }
+
+  client_filter {
+    time_window {
+      start = "2024-01-01T00:00:00Z"
+    }
+  }
+}
+
+data "akc_feature_evaluation" "alice" {
+  endpoint  = akc_feature.test.endpoint
+  name      = akc_feature.test.name
+  user      = "alice"
+  timestamp = "2024-06-01T00:00:00Z"
+}
+
+data "akc_feature_evaluation" "beta" {
+  endpoint  = akc_feature.test.endpoint
+  name      = akc_feature.test.name
+  user      = "carol"
+  groups    = ["beta"]
+  timestamp = "2024-06-01T00:00:00Z"
+}
+
+data "akc_feature_evaluation" "bob" {
+  endpoint  = akc_feature.test.endpoint
+  name      = akc_feature.test.name
+  user      = "bob"
+  timestamp = "2024-06-01T00:00:00Z"
+}
+
+data "akc_feature_evaluation" "too_early" {
+  endpoint  = akc_feature.test.endpoint
+  name      = akc_feature.test.name
+  user      = "alice"
+  timestamp = "2023-06-01T00:00:00Z"
+}
+`, endpointUnderTest, name)
+}
+
+func TestFeatureEvaluation_percentageIsAnError(t *testing.T) {
+	if testEmulator == nil {
+		t.Skip("the evaluation is checked on the emulator")
+	}
+
+	meta, _ := emulatorConfigure(context.Background(), nil)
+	cl, _ := getClient(endpointUnderTest, meta.(func(endpoint string) (*client.Client, error)))
+	name := uuid.New().String()
+	feature := client.Feature{Enabled: true, Conditions: client.FeatureConditions{ClientFilters: []client.FeatureFilter{client.PercentageFilter(50)}}}
+	if _, err := cl.SetFeatureFlag(name, client.LabelNone, feature); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	r := dataSourceFeatureEvaluation()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"endpoint": endpointUnderTest,
+		"name":     name,
+	})
+	if diags := r.ReadContext(context.Background(), d, meta); !diags.HasError() {
+		t.Errorf("a random filter should not be evaluated, got enabled = %t", d.Get("enabled").(bool))
+	}
+}
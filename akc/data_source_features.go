@@ -0,0 +1,170 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceFeatures() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFeaturesRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Description: "Only return the features whose name starts with this prefix, all features if empty",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeMap,
+				Description: "Enabled state of the features, by name",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeBool,
+				},
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Description: "JSON value of the features, by name",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"features": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"requirement_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"client_filter": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"parameters": {
+										Type:        schema.TypeString,
+										Description: "JSON encoded parameters of the filter",
+										Computed:    true,
+									},
+								},
+							},
+						},
+						"locked": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"last_modified": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceFeaturesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	label := d.Get("label").(string)
+	nameFilter := prefixFilter(d.Get("prefix").(string))
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	features, err := cl.ListFeaturesContext(ctx, nameFilter, client.EscapeFilter(label))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration features %s/%s", label, nameFilter), err)
+	}
+
+	enabled := map[string]bool{}
+	values := map[string]string{}
+	items := make([]map[string]interface{}, 0, len(features))
+	for _, feature := range features {
+		enabled[feature.Key] = feature.Enabled
+		values[feature.Key] = string(feature.Raw)
+
+		items = append(items, map[string]interface{}{
+			"name":             feature.Key,
+			"label":            labelOrNone(feature.Label),
+			"description":      feature.Description,
+			"enabled":          feature.Enabled,
+			"requirement_type": requirementTypeOrAny(feature.Conditions.RequirementType),
+			"client_filter":    flattenFeatureFilterItems(feature.Conditions.ClientFilters),
+			"locked":           feature.Locked,
+			"last_modified":    feature.LastModified,
+		})
+	}
+
+	id, err := formatFeatureID(endpoint, label, nameFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("enabled", enabled)
+	d.Set("values", values)
+	if err := d.Set("features", items); err != nil {
+		return errorDiagnostics("error setting features", err)
+	}
+
+	log.Printf("[INFO] %d features have been fetched %s/%s/%s", len(features), endpoint, label, nameFilter)
+
+	return nil
+}
+
+func flattenFeatureFilterItems(filters []client.FeatureFilter) []interface{} {
+	result := []interface{}{}
+	for _, filter := range filters {
+		flattened := flattenCustomFeatureFilter(filter)
+		result = append(result, map[string]interface{}{
+			"name":       flattened["name"],
+			"parameters": flattened["parameters"],
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,42 @@
+package akc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceFeatures_prefixAndLabel(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	descriptions := map[string]string{
+		"One": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+		"Two": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigDataSourceFeatures(label, prefix, descriptions),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.akc_features.test", "id"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "enabled.%", "2"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "enabled."+prefix+"One", "true"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "values.%", "2"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.#", "2"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.name", prefix+"One"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.label", label),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.description", descriptions["One"]),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.enabled", "true"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.client_filter.#", "1"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.client_filter.0.name", "Microsoft.Percentage"),
+					resource.TestCheckResourceAttr("data.akc_features.test", "features.0.client_filter.0.parameters", `{"Value":50}`),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceKeyRevisions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceKeyRevisionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Description: "Key filter, accepts wildcards (e.g. MyApp:*)",
+				Required:    true,
+			},
+			"label": {
+				Type:        schema.TypeString,
+				Description: "Label filter, accepts wildcards and comma-separated labels",
+				Optional:    true,
+				Default:     client.LabelNone,
+			},
+			"from": {
+				Type:         schema.TypeString,
+				Description:  "Only return the revisions made at or after this RFC3339 timestamp",
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"to": {
+				Type:         schema.TypeString,
+				Description:  "Only return the revisions made at or before this RFC3339 timestamp",
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"revisions": keyValueItemsSchema(),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceKeyRevisionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	key := d.Get("key").(string)
+	label := d.Get("label").(string)
+
+	from, err := parseOptionalTime(d.Get("from").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	to, err := parseOptionalTime(d.Get("to").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	// the filters are passed as they are, their wildcards and comma-separated labels being documented
+	revisions, err := cl.ListRevisionsContext(ctx, key, label, from, to)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration revisions %s/%s", label, key), err)
+	}
+
+	id, err := formatID(endpoint, label, key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	if err := d.Set("revisions", flattenKeyValueItems(revisions)); err != nil {
+		return errorDiagnostics("error setting revisions", err)
+	}
+
+	log.Printf("[INFO] %d revisions have been fetched %s/%s/%s", len(revisions), endpoint, label, key)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package akc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccDataSourceKeyRevisions_basic(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	key := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	value := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	newValue := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigWithLabel(label, key, value),
+			},
+			{
+				Config: buildTerraformConfigDataSourceKeyRevisions(label, key, newValue),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.akc_key_revisions.test", "id"),
+					resource.TestCheckResourceAttr("data.akc_key_revisions.test", "revisions.#", "2"),
+					resource.TestCheckResourceAttr("data.akc_key_revisions.test", "revisions.0.key", key),
+					resource.TestCheckResourceAttr("data.akc_key_revisions.test", "revisions.0.label", label),
+					resource.TestCheckResourceAttr("data.akc_key_revisions.test", "revisions.0.value", newValue),
+					resource.TestCheckResourceAttr("data.akc_key_revisions.test", "revisions.1.value", value),
+					resource.TestCheckResourceAttrSet("data.akc_key_revisions.test", "revisions.0.last_modified"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceKeyRevisions_wildcards(t *testing.T) {
+	if testEmulator == nil {
+		t.Skip("the data source is only read directly against the emulator")
+	}
+
+	meta, _ := emulatorConfigure(context.Background(), nil)
+	cl, _ := meta.(func(endpoint string) (*client.Client, error))(endpointUnderTest)
+	prefix := uuid.New().String() + ":"
+	labels := []string{uuid.New().String(), uuid.New().String()}
+
+	for _, label := range labels {
+		for _, key := range []string{"one", "two"} {
+			if _, err := cl.SetKeyValue(label, prefix+key, "value"); err != nil {
+				t.Fatalf("%+v", err)
+			}
+		}
+	}
+
+	r := dataSourceKeyRevisions()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"endpoint": endpointUnderTest,
+		"key":      prefix + "*",
+		"label":    labels[0] + "," + labels[1],
+	})
+	if diags := r.ReadContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+
+	if count := d.Get("revisions.#").(int); count != 4 {
+		t.Errorf("the key wildcard and the labels should match 4 revisions, got %d", count)
+	}
+}
@@ -0,0 +1,148 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceKeyValues() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceKeyValuesRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Description: "Only return the keys starting with this prefix (e.g. MyApp:), all keys if empty",
+				Optional:    true,
+			},
+			"labels": {
+				Type:        schema.TypeList,
+				Description: "Labels to look for, the last one wins when a key exists with several of them. Defaults to no label",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"values": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"items": keyValueItemsSchema(),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceKeyValuesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	keyFilter := prefixFilter(d.Get("prefix").(string))
+	labels := labelsOrNone(d.Get("labels").([]interface{}))
+	labelFilter := labelsFilter(labels)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	kvs, err := cl.ListKeyValuesContext(ctx, keyFilter, labelFilter)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration keys %s/%s", labelFilter, keyFilter), err)
+	}
+
+	sortByLabelPrecedence(kvs, labels)
+
+	values := map[string]string{}
+	for _, kv := range kvs {
+		values[kv.Key] = kv.Value
+	}
+
+	id, err := formatID(endpoint, labelFilter, keyFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("values", values)
+	if err := d.Set("items", flattenKeyValueItems(kvs)); err != nil {
+		return errorDiagnostics("error setting items", err)
+	}
+
+	log.Printf("[INFO] %d key-values have been fetched %s/%s/%s", len(kvs), endpoint, labelFilter, keyFilter)
+
+	return nil
+}
+
+// prefixFilter turns a key prefix into an App Configuration key filter, escaping its reserved characters.
+// A trailing wildcard is accepted, the prefix being the same with or without it.
+func prefixFilter(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	return client.EscapeFilter(strings.TrimSuffix(prefix, "*")) + "*"
+}
+
+// labelsFilter turns a list of labels into an App Configuration label filter
+func labelsFilter(labels []string) string {
+	escaped := []string{}
+	for _, label := range labels {
+		escaped = append(escaped, client.EscapeFilter(label))
+	}
+
+	return strings.Join(escaped, ",")
+}
+
+func labelsOrNone(raw []interface{}) []string {
+	labels := []string{}
+	for _, label := range raw {
+		labels = append(labels, label.(string))
+	}
+
+	if len(labels) == 0 {
+		return []string{client.LabelNone}
+	}
+
+	return labels
+}
+
+// labelOrNone maps the empty label returned by App Configuration to the value used in the configuration
+func labelOrNone(label string) string {
+	if label == "" {
+		return client.LabelNone
+	}
+
+	return label
+}
+
+// sortByLabelPrecedence orders the key-values by key, then by the position of their label in the given list,
+// so that walking through them lets the last label win
+func sortByLabelPrecedence(kvs []client.KeyValueResponse, labels []string) {
+	rank := map[string]int{}
+	for i, label := range labels {
+		rank[label] = i
+	}
+
+	sort.SliceStable(kvs, func(i, j int) bool {
+		if kvs[i].Key != kvs[j].Key {
+			return kvs[i].Key < kvs[j].Key
+		}
+
+		return rank[labelOrNone(kvs[i].Label)] < rank[labelOrNone(kvs[j].Label)]
+	})
+}
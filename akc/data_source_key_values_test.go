@@ -0,0 +1,40 @@
+package akc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKeyValues_prefixAndLabel(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum) + ":"
+	values := map[string]string{
+		"one": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+		"two": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigDataSourceKeyValues(label, prefix, values),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.akc_key_values.test", "id"),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "values.%", "2"),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "values."+prefix+"one", values["one"]),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "values."+prefix+"two", values["two"]),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "items.#", "2"),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "items.0.key", prefix+"one"),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "items.0.label", label),
+					resource.TestCheckResourceAttr("data.akc_key_values.test", "items.0.value", values["one"]),
+					resource.TestCheckResourceAttrSet("data.akc_key_values.test", "items.0.content_type"),
+					resource.TestCheckResourceAttrSet("data.akc_key_values.test", "items.0.last_modified"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceKeys() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceKeysRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Description: "Only return the keys starting with this prefix (e.g. MyApp:), all keys if empty",
+				Optional:    true,
+			},
+			"keys": {
+				Type:        schema.TypeList,
+				Description: "Distinct keys whatever their label, sorted",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceKeysRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	nameFilter := prefixFilter(d.Get("prefix").(string))
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	keys, err := cl.ListKeysContext(ctx, nameFilter)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration keys %s", nameFilter), err)
+	}
+	sort.Strings(keys)
+
+	id, err := formatListID(endpoint, "keys", nameFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("keys", keys)
+
+	log.Printf("[INFO] %d keys have been fetched %s/%s", len(keys), endpoint, nameFilter)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package akc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKeys_prefix(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum) + ":"
+	values := map[string]string{
+		"one": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+		"two": acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum),
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigDataSourceKeys(label, prefix, values),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.akc_keys.test", "id"),
+					resource.TestCheckResourceAttr("data.akc_keys.test", "keys.#", "2"),
+					resource.TestCheckResourceAttr("data.akc_keys.test", "keys.0", prefix+"one"),
+					resource.TestCheckResourceAttr("data.akc_keys.test", "keys.1", prefix+"two"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLabels() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceLabelsRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Description: "Only return the labels starting with this prefix, all labels if empty",
+				Optional:    true,
+			},
+			"labels": {
+				Type:        schema.TypeList,
+				Description: "Labels in use, sorted, the key-values without label being listed as %00",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceLabelsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	nameFilter := prefixFilter(d.Get("prefix").(string))
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	result, err := cl.ListLabelsContext(ctx, nameFilter)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration labels %s", nameFilter), err)
+	}
+
+	labels := []string{}
+	for _, label := range result {
+		labels = append(labels, labelOrNone(label))
+	}
+	sort.Strings(labels)
+
+	id, err := formatListID(endpoint, "labels", nameFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("labels", labels)
+
+	log.Printf("[INFO] %d labels have been fetched %s/%s", len(labels), endpoint, nameFilter)
+
+	return nil
+}
+
+// formatListID identifies the result of a data source listing the names of a kind of item matching a filter
+func formatListID(endpoint string, kind string, filter string) (string, error) {
+	base, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s/%s", base, kind, filter), nil
+}
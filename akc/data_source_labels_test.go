@@ -0,0 +1,34 @@
+package akc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceLabels_prefix(t *testing.T) {
+	key := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	labels := map[string]string{
+		"dev":  prefix + "-dev",
+		"prod": prefix + "-prod",
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigDataSourceLabels(key, prefix, labels),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.akc_labels.test", "id"),
+					resource.TestCheckResourceAttr("data.akc_labels.test", "labels.#", "2"),
+					resource.TestCheckResourceAttr("data.akc_labels.test", "labels.0", labels["dev"]),
+					resource.TestCheckResourceAttr("data.akc_labels.test", "labels.1", labels["prod"]),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceSnapshot() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSnapshotRead,
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"composition_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"expires": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"values": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"items": keyValueItemsSchema(),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func dataSourceSnapshotRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint := d.Get("endpoint").(string)
+	name := d.Get("name").(string)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	snapshot, err := cl.GetSnapshotContext(ctx, name)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting App Configuration snapshot %s", name), err)
+	}
+
+	kvs, err := cl.ListSnapshotKeyValuesContext(ctx, name)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing the key-values of App Configuration snapshot %s", name), err)
+	}
+
+	values := map[string]string{}
+	for _, kv := range kvs {
+		values[kv.Key] = kv.Value
+	}
+
+	id, err := formatSnapshotID(endpoint, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("status", snapshot.Status)
+	d.Set("composition_type", snapshot.CompositionType)
+	d.Set("created", snapshot.Created)
+	d.Set("expires", snapshot.Expires)
+	d.Set("values", values)
+	if err := d.Set("items", flattenKeyValueItems(kvs)); err != nil {
+		return errorDiagnostics("error setting items", err)
+	}
+
+	log.Printf("[INFO] %d key-values have been fetched from snapshot %s/%s", len(kvs), endpoint, name)
+
+	return nil
+}
@@ -0,0 +1,368 @@
+package akc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// featureFilterSchema describes the client filters of a feature flag, either custom ones given by name and JSON
+// parameters, or built-in ones given by a typed block
+func featureFilterSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "Client filters, the feature being on when any of them (or all of them, depending on requirement_type) matches",
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Description: "Name of a custom filter, leave empty when using a targeting, time_window or percentage block",
+					Optional:    true,
+				},
+				"parameters": {
+					Type:             schema.TypeString,
+					Description:      "JSON encoded parameters of a custom filter",
+					Optional:         true,
+					ValidateFunc:     validation.StringIsJSON,
+					DiffSuppressFunc: structure.SuppressJsonDiff,
+				},
+				"targeting": {
+					Type:        schema.TypeList,
+					Description: "Microsoft.Targeting filter",
+					Optional:    true,
+					MaxItems:    1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"users": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"group": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem: &schema.Resource{
+									Schema: map[string]*schema.Schema{
+										"name": {
+											Type:     schema.TypeString,
+											Required: true,
+										},
+										"rollout_percentage": {
+											Type:         schema.TypeInt,
+											Required:     true,
+											ValidateFunc: validation.IntBetween(0, 100),
+										},
+									},
+								},
+							},
+							"default_rollout_percentage": {
+								Type:         schema.TypeInt,
+								Optional:     true,
+								Default:      0,
+								ValidateFunc: validation.IntBetween(0, 100),
+							},
+							"excluded_users": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"excluded_groups": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+				"time_window": {
+					Type:        schema.TypeList,
+					Description: "Microsoft.TimeWindow filter",
+					Optional:    true,
+					MaxItems:    1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"start": {
+								Type:             schema.TypeString,
+								Description:      "RFC3339 timestamp the feature is on from, open if empty",
+								Optional:         true,
+								ValidateFunc:     validation.IsRFC3339Time,
+								DiffSuppressFunc: suppressSameTime,
+							},
+							"end": {
+								Type:             schema.TypeString,
+								Description:      "RFC3339 timestamp the feature is on until, open if empty",
+								Optional:         true,
+								ValidateFunc:     validation.IsRFC3339Time,
+								DiffSuppressFunc: suppressSameTime,
+							},
+						},
+					},
+				},
+				"percentage": {
+					Type:        schema.TypeList,
+					Description: "Microsoft.Percentage filter",
+					Optional:    true,
+					MaxItems:    1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"value": {
+								Type:         schema.TypeInt,
+								Required:     true,
+								ValidateFunc: validation.IntBetween(0, 100),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandFeatureFilters(raw []interface{}) ([]client.FeatureFilter, error) {
+	filters := []client.FeatureFilter{}
+	for i, r := range raw {
+		block, _ := r.(map[string]interface{})
+		if block == nil {
+			return nil, fmt.Errorf("client_filter %d: a name or a typed block is required", i)
+		}
+
+		filter, err := expandFeatureFilter(block)
+		if err != nil {
+			return nil, fmt.Errorf("client_filter %d: %+v", i, err)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+func expandFeatureFilter(block map[string]interface{}) (client.FeatureFilter, error) {
+	typed := 0
+	for _, name := range []string{"targeting", "time_window", "percentage"} {
+		if len(block[name].([]interface{})) > 0 {
+			typed++
+		}
+	}
+
+	name := block["name"].(string)
+	parameters := block["parameters"].(string)
+	if typed > 1 || (typed == 1 && (name != "" || parameters != "")) {
+		return client.FeatureFilter{}, fmt.Errorf("a filter is either a custom one, or one of targeting, time_window and percentage")
+	}
+
+	if targeting := block["targeting"].([]interface{}); len(targeting) > 0 {
+		return client.TargetingFilter(expandTargetingAudience(targeting[0]))
+	}
+
+	if window := block["time_window"].([]interface{}); len(window) > 0 {
+		if window[0] == nil {
+			return client.TimeWindowFilter(time.Time{}, time.Time{}), nil
+		}
+
+		boundaries := window[0].(map[string]interface{})
+		start, err := parseOptionalTime(boundaries["start"].(string))
+		if err != nil {
+			return client.FeatureFilter{}, err
+		}
+
+		end, err := parseOptionalTime(boundaries["end"].(string))
+		if err != nil {
+			return client.FeatureFilter{}, err
+		}
+
+		return client.TimeWindowFilter(start, end), nil
+	}
+
+	if percentage := block["percentage"].([]interface{}); len(percentage) > 0 {
+		return client.PercentageFilter(percentage[0].(map[string]interface{})["value"].(int)), nil
+	}
+
+	if name == "" {
+		return client.FeatureFilter{}, fmt.Errorf("a name or a typed block is required")
+	}
+
+	filter := client.FeatureFilter{Name: name}
+	if parameters != "" {
+		if err := json.Unmarshal([]byte(parameters), &filter.Parameters); err != nil {
+			return client.FeatureFilter{}, fmt.Errorf("invalid parameters: %+v", err)
+		}
+	}
+
+	return filter, nil
+}
+
+func expandTargetingAudience(raw interface{}) client.TargetingAudience {
+	audience := client.TargetingAudience{
+		Users:  []string{},
+		Groups: []client.TargetingGroup{},
+	}
+	if raw == nil {
+		return audience
+	}
+
+	block := raw.(map[string]interface{})
+	audience.Users = expandStrings(block["users"].([]interface{}))
+	audience.DefaultRolloutPercentage = block["default_rollout_percentage"].(int)
+
+	for _, g := range block["group"].([]interface{}) {
+		group := g.(map[string]interface{})
+		audience.Groups = append(audience.Groups, client.TargetingGroup{
+			Name:              group["name"].(string),
+			RolloutPercentage: group["rollout_percentage"].(int),
+		})
+	}
+
+	excludedUsers := expandStrings(block["excluded_users"].([]interface{}))
+	excludedGroups := expandStrings(block["excluded_groups"].([]interface{}))
+	if len(excludedUsers) > 0 || len(excludedGroups) > 0 {
+		audience.Exclusion = &client.TargetingExclusion{
+			Users:  excludedUsers,
+			Groups: excludedGroups,
+		}
+	}
+
+	return audience
+}
+
+// flattenFeatureFilters keeps each filter in the form it was configured with, the built-in filters being read
+// into their typed block unless they were configured as custom ones
+func flattenFeatureFilters(filters []client.FeatureFilter, previous []interface{}) []interface{} {
+	result := []interface{}{}
+	for i, filter := range filters {
+		custom := false
+		if i < len(previous) && previous[i] != nil {
+			custom = previous[i].(map[string]interface{})["name"].(string) == filter.Name
+		}
+
+		block := flattenTypedFeatureFilter(filter)
+		if custom || block == nil {
+			block = flattenCustomFeatureFilter(filter)
+		}
+
+		result = append(result, block)
+	}
+
+	return result
+}
+
+// flattenTypedFeatureFilter returns nil for the filters which are not built-in, or whose parameters are not the expected ones
+func flattenTypedFeatureFilter(filter client.FeatureFilter) map[string]interface{} {
+	block := map[string]interface{}{
+		"name":        "",
+		"parameters":  "",
+		"targeting":   []interface{}{},
+		"time_window": []interface{}{},
+		"percentage":  []interface{}{},
+	}
+
+	switch filter.Name {
+	case client.TargetingFilterName:
+		audience, err := filter.Targeting()
+		if err != nil {
+			return nil
+		}
+
+		groups := []interface{}{}
+		for _, group := range audience.Groups {
+			groups = append(groups, map[string]interface{}{
+				"name":               group.Name,
+				"rollout_percentage": group.RolloutPercentage,
+			})
+		}
+
+		targeting := map[string]interface{}{
+			"users":                      audience.Users,
+			"group":                      groups,
+			"default_rollout_percentage": audience.DefaultRolloutPercentage,
+			"excluded_users":             []string{},
+			"excluded_groups":            []string{},
+		}
+		if audience.Exclusion != nil {
+			targeting["excluded_users"] = audience.Exclusion.Users
+			targeting["excluded_groups"] = audience.Exclusion.Groups
+		}
+
+		block["targeting"] = []interface{}{targeting}
+
+	case client.TimeWindowFilterName:
+		start, end, err := filter.TimeWindow()
+		if err != nil {
+			return nil
+		}
+
+		block["time_window"] = []interface{}{map[string]interface{}{
+			"start": formatOptionalTime(start),
+			"end":   formatOptionalTime(end),
+		}}
+
+	case client.PercentageFilterName:
+		value, err := filter.Percentage()
+		if err != nil {
+			return nil
+		}
+
+		block["percentage"] = []interface{}{map[string]interface{}{
+			"value": value,
+		}}
+
+	default:
+		return nil
+	}
+
+	return block
+}
+
+func flattenCustomFeatureFilter(filter client.FeatureFilter) map[string]interface{} {
+	parameters := ""
+	if len(filter.Parameters) > 0 {
+		b, _ := json.Marshal(filter.Parameters)
+		parameters = string(b)
+	}
+
+	return map[string]interface{}{
+		"name":        filter.Name,
+		"parameters":  parameters,
+		"targeting":   []interface{}{},
+		"time_window": []interface{}{},
+		"percentage":  []interface{}{},
+	}
+}
+
+func expandStrings(raw []interface{}) []string {
+	result := []string{}
+	for _, r := range raw {
+		result = append(result, r.(string))
+	}
+
+	return result
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// suppressSameTime ignores the differences between two RFC3339 timestamps designating the same time
+func suppressSameTime(k, old, new string, d *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+
+	newTime, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+
+	return oldTime.Equal(newTime)
+}
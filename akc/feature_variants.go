@@ -0,0 +1,326 @@
+package akc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// featureVariantSchema describes the variants of a feature flag, their configuration value being JSON encoded
+func featureVariantSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "Variants of the feature, assigned to the users by the allocation",
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"configuration_value": {
+					Type:             schema.TypeString,
+					Description:      "JSON encoded configuration value of the variant",
+					Optional:         true,
+					ValidateFunc:     validation.StringIsJSON,
+					DiffSuppressFunc: structure.SuppressJsonDiff,
+				},
+				"status_override": {
+					Type:         schema.TypeString,
+					Description:  "Overrides the enabled state of the feature when the variant is assigned",
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice([]string{client.VariantStatusNone, client.VariantStatusEnabled, client.VariantStatusDisabled}, false),
+				},
+			},
+		},
+	}
+}
+
+// featureAllocationSchema describes how the variants of a feature flag are assigned to the users
+func featureAllocationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"default_when_enabled": {
+					Type:        schema.TypeString,
+					Description: "Variant assigned when the feature is on and no other allocation applies",
+					Optional:    true,
+				},
+				"default_when_disabled": {
+					Type:        schema.TypeString,
+					Description: "Variant assigned when the feature is off",
+					Optional:    true,
+				},
+				"user": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"variant": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"users": {
+								Type:     schema.TypeList,
+								Required: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+				"group": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"variant": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"groups": {
+								Type:     schema.TypeList,
+								Required: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+				"percentile": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"variant": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"from": {
+								Type:         schema.TypeFloat,
+								Required:     true,
+								ValidateFunc: validation.FloatBetween(0, 100),
+							},
+							"to": {
+								Type:         schema.TypeFloat,
+								Required:     true,
+								ValidateFunc: validation.FloatBetween(0, 100),
+							},
+						},
+					},
+				},
+				"seed": {
+					Type:        schema.TypeString,
+					Description: "Seed of the percentile allocation, the feature name being used by default",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+func featureTelemetrySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Type:     schema.TypeBool,
+					Required: true,
+				},
+				"metadata": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// resourceFeatureCustomizeDiff validates the allocation at plan time, as long as the variants are known
+func resourceFeatureCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"variant", "allocation"} {
+		if !diff.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	for i := range diff.Get("variant").([]interface{}) {
+		if !diff.NewValueKnown(fmt.Sprintf("variant.%d.name", i)) {
+			return nil
+		}
+	}
+
+	variants, err := expandFeatureVariants(diff.Get("variant").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	feature := client.Feature{
+		Variants:   variants,
+		Allocation: expandFeatureAllocation(diff.Get("allocation").([]interface{})),
+	}
+
+	return feature.Validate()
+}
+
+func expandFeatureVariants(raw []interface{}) ([]client.FeatureVariant, error) {
+	variants := []client.FeatureVariant{}
+	for _, r := range raw {
+		block := r.(map[string]interface{})
+
+		variant := client.FeatureVariant{
+			Name:           block["name"].(string),
+			StatusOverride: block["status_override"].(string),
+		}
+
+		if value := block["configuration_value"].(string); value != "" {
+			if err := json.Unmarshal([]byte(value), &variant.ConfigurationValue); err != nil {
+				return nil, fmt.Errorf("invalid configuration_value of the variant %s: %+v", variant.Name, err)
+			}
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+func expandFeatureAllocation(raw []interface{}) *client.FeatureAllocation {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil
+	}
+
+	block := raw[0].(map[string]interface{})
+	allocation := &client.FeatureAllocation{
+		DefaultWhenEnabled:  block["default_when_enabled"].(string),
+		DefaultWhenDisabled: block["default_when_disabled"].(string),
+		Seed:                block["seed"].(string),
+	}
+
+	for _, u := range block["user"].([]interface{}) {
+		user := u.(map[string]interface{})
+		allocation.User = append(allocation.User, client.UserAllocation{
+			Variant: user["variant"].(string),
+			Users:   expandStrings(user["users"].([]interface{})),
+		})
+	}
+
+	for _, g := range block["group"].([]interface{}) {
+		group := g.(map[string]interface{})
+		allocation.Group = append(allocation.Group, client.GroupAllocation{
+			Variant: group["variant"].(string),
+			Groups:  expandStrings(group["groups"].([]interface{})),
+		})
+	}
+
+	for _, p := range block["percentile"].([]interface{}) {
+		percentile := p.(map[string]interface{})
+		allocation.Percentile = append(allocation.Percentile, client.PercentileAllocation{
+			Variant: percentile["variant"].(string),
+			From:    percentile["from"].(float64),
+			To:      percentile["to"].(float64),
+		})
+	}
+
+	return allocation
+}
+
+func expandFeatureTelemetry(raw []interface{}) *client.FeatureTelemetry {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil
+	}
+
+	block := raw[0].(map[string]interface{})
+	telemetry := &client.FeatureTelemetry{
+		Enabled: block["enabled"].(bool),
+	}
+
+	if metadata := block["metadata"].(map[string]interface{}); len(metadata) > 0 {
+		telemetry.Metadata = map[string]string{}
+		for key, value := range metadata {
+			telemetry.Metadata[key] = value.(string)
+		}
+	}
+
+	return telemetry
+}
+
+func flattenFeatureVariants(variants []client.FeatureVariant) []interface{} {
+	result := []interface{}{}
+	for _, variant := range variants {
+		value := ""
+		if variant.ConfigurationValue != nil {
+			b, _ := json.Marshal(variant.ConfigurationValue)
+			value = string(b)
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":                variant.Name,
+			"configuration_value": value,
+			"status_override":     variant.StatusOverride,
+		})
+	}
+
+	return result
+}
+
+func flattenFeatureAllocation(allocation *client.FeatureAllocation) []interface{} {
+	if allocation == nil {
+		return []interface{}{}
+	}
+
+	users := []interface{}{}
+	for _, user := range allocation.User {
+		users = append(users, map[string]interface{}{
+			"variant": user.Variant,
+			"users":   user.Users,
+		})
+	}
+
+	groups := []interface{}{}
+	for _, group := range allocation.Group {
+		groups = append(groups, map[string]interface{}{
+			"variant": group.Variant,
+			"groups":  group.Groups,
+		})
+	}
+
+	percentiles := []interface{}{}
+	for _, percentile := range allocation.Percentile {
+		percentiles = append(percentiles, map[string]interface{}{
+			"variant": percentile.Variant,
+			"from":    percentile.From,
+			"to":      percentile.To,
+		})
+	}
+
+	return []interface{}{map[string]interface{}{
+		"default_when_enabled":  allocation.DefaultWhenEnabled,
+		"default_when_disabled": allocation.DefaultWhenDisabled,
+		"user":                  users,
+		"group":                 groups,
+		"percentile":            percentiles,
+		"seed":                  allocation.Seed,
+	}}
+}
+
+func flattenFeatureTelemetry(telemetry *client.FeatureTelemetry) []interface{} {
+	if telemetry == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{map[string]interface{}{
+		"enabled":  telemetry.Enabled,
+		"metadata": telemetry.Metadata,
+	}}
+}
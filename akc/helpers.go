@@ -1,17 +1,272 @@
-package akc
-
-import (
-	"time"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-)
-
-func getClient(endpoint string, clientBuilder func(endpoint string) (*client.Client, error)) (*client.Client, error) {
-	cl, err := clientBuilder(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return cl, nil
-}
-
-const readTimeout = 20 * time.Second
+package akc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// getClient builds a client for the normalized endpoint, whatever the way it is spelled in the configuration
+func getClient(endpoint string, clientBuilder func(endpoint string) (*client.Client, error)) (*client.Client, error) {
+	endpoint, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := clientBuilder(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+const (
+	// defaultReadTimeout bounds a whole read, its pages and its retries included, as the SDK does by default
+	defaultReadTimeout = 20 * time.Minute
+	// readTimeout bounds the retries of the read of an item which is not visible yet
+	readTimeout = 20 * time.Second
+)
+
+// readNewResource reads an item of a resource. Right after its creation, the read is retried while the item
+// is not found, since it may not be visible yet. Otherwise a not-found error is returned at once, the item having
+// actually been deleted.
+func readNewResource(ctx context.Context, d *schema.ResourceData, what string, read func() error) error {
+	if !d.IsNewResource() {
+		return read()
+	}
+
+	return resource.RetryContext(ctx, readTimeout, func() *resource.RetryError {
+		err := read()
+		if client.IsNotFound(err) {
+			log.Printf("[INFO] retrying to get the new %s because: %s", what, err)
+
+			return resource.RetryableError(err)
+		}
+
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		return nil
+	})
+}
+
+// errorDiagnostics reports a failure, the summary telling what failed and the detail why
+func errorDiagnostics(summary string, err error) diag.Diagnostics {
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   err.Error(),
+		},
+	}
+}
+
+// keyValueItemsSchema describes a list of key-values returned by a data source
+func keyValueItemsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"label": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"value": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"content_type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tags": {
+					Type:     schema.TypeMap,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"last_modified": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func flattenKeyValueItems(kvs []client.KeyValueResponse) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(kvs))
+	for _, kv := range kvs {
+		items = append(items, map[string]interface{}{
+			"key":           kv.Key,
+			"label":         labelOrNone(kv.Label),
+			"value":         kv.Value,
+			"content_type":  kv.ContentType,
+			"tags":          kv.Tags,
+			"last_modified": kv.LastModified,
+		})
+	}
+
+	return items
+}
+
+// parseOptionalTime parses an RFC3339 timestamp, the empty string giving the zero time
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the timestamp %s: %+v", value, err)
+	}
+
+	return t, nil
+}
+
+// asOfOptions gives the options making a read return the key as it was at the given RFC3339 timestamp, if any
+func asOfOptions(asOf string) ([]client.RequestOption, error) {
+	t, err := parseOptionalTime(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	return []client.RequestOption{client.AsOf(t)}, nil
+}
+
+// keyDiagnostics turns the errors of a write on a key into a diagnostic the user can act on
+func keyDiagnostics(err error, endpoint string, label string, key string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	switch {
+	case client.IsPreconditionFailed(err):
+		diags = changedOutsideDiagnostics(fmt.Sprintf("the key %s/%s/%s", endpoint, label, key), err)
+	case client.IsLocked(err):
+		diags = diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("the key %s/%s/%s is locked outside Terraform", endpoint, label, key),
+				Detail:   fmt.Sprintf("Set locked = true to let Terraform unlock it before writing.\n\n%s", err),
+			},
+		}
+	default:
+		diags = errorDiagnostics(fmt.Sprintf("error writing the key %s/%s/%s", endpoint, label, key), err)
+	}
+
+	var unlocked *leftUnlockedError
+	if errors.As(err, &unlocked) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("the key %s/%s/%s was left unlocked", endpoint, label, key),
+			Detail:   fmt.Sprintf("It could not be locked back after the failed write, lock it again or apply again.\n\n%s", unlocked.relockErr),
+		})
+	}
+
+	return diags
+}
+
+// changedOutsideDiagnostics reports a write refused because the item changed since Terraform last read it
+func changedOutsideDiagnostics(item string, err error) diag.Diagnostics {
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s was changed outside Terraform since it was last read", item),
+			Detail:   fmt.Sprintf("Refresh the state and apply again.\n\n%s", err),
+		},
+	}
+}
+
+// lockable locks or unlocks a key, be it a key-value or a feature flag
+type lockable struct {
+	lock   func(options ...client.RequestOption) (client.KeyValueResponse, error)
+	unlock func(options ...client.RequestOption) (client.KeyValueResponse, error)
+}
+
+func keyValueLock(ctx context.Context, cl *client.Client, label string, key string) lockable {
+	return lockable{
+		lock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+			return cl.LockKeyValueContext(ctx, label, key, options...)
+		},
+		unlock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+			return cl.UnlockKeyValueContext(ctx, label, key, options...)
+		},
+	}
+}
+
+func featureLock(ctx context.Context, cl *client.Client, label string, name string) lockable {
+	return lockable{
+		lock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+			return cl.LockFeatureContext(ctx, label, name, options...)
+		},
+		unlock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+			return cl.UnlockFeatureContext(ctx, label, name, options...)
+		},
+	}
+}
+
+// leftUnlockedError is the failure of a write on a key which could not be locked back afterwards
+type leftUnlockedError struct {
+	err       error
+	relockErr error
+}
+
+func (e *leftUnlockedError) Error() string {
+	return fmt.Sprintf("%s (the key was left unlocked: %s)", e.err, e.relockErr)
+}
+
+func (e *leftUnlockedError) Unwrap() error {
+	return e.err
+}
+
+// writeUnlocked runs write (if any) against a key which is unlocked first when it was locked, and locked afterwards when requested.
+// Each step is conditioned by the ETag returned by the previous one, starting with the given one.
+// When the write fails on a key it unlocked, the key is locked back.
+func writeUnlocked(l lockable, etag string, wasLocked bool, lock bool, write func(etag string) (client.KeyValueResponse, error)) error {
+	unlocked := false
+	if wasLocked && (write != nil || !lock) {
+		kv, err := l.unlock(client.IfMatch(etag))
+		if err != nil {
+			return err
+		}
+		etag = kv.ETag
+		unlocked = true
+	}
+
+	if write != nil {
+		kv, err := write(etag)
+		if err != nil && unlocked {
+			if _, relockErr := l.lock(client.IfMatch(etag)); relockErr != nil {
+				return &leftUnlockedError{err: err, relockErr: relockErr}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		etag = kv.ETag
+	}
+
+	if lock && (write != nil || !wasLocked) {
+		if _, err := l.lock(client.IfMatch(etag)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
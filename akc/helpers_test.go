@@ -0,0 +1,206 @@
+package akc
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type readTest struct {
+	name     string
+	resource *schema.Resource
+	raw      map[string]interface{}
+}
+
+func readTests() []readTest {
+	return []readTest{
+		{"akc_key_value", resourceKeyValue(), map[string]interface{}{"key": uuid.New().String(), "value": "value"}},
+		{"akc_key_secret", resourceKeySecret(), map[string]interface{}{"key": uuid.New().String(), "secret_id": "https://my-vault.vault.azure.net/secrets/my-secret"}},
+		{"akc_feature", resourceFeature(), map[string]interface{}{"name": uuid.New().String(), "enabled": true}},
+	}
+}
+
+func createForReadTest(t *testing.T, test readTest) (*schema.ResourceData, interface{}) {
+	if testEmulator == nil {
+		t.Skip("failures can only be injected on the emulator")
+	}
+
+	meta, _ := emulatorConfigure(context.Background(), nil)
+	test.raw["endpoint"] = endpointUnderTest
+	test.raw["label"] = uuid.New().String()
+
+	d := schema.TestResourceDataRaw(t, test.resource.Schema, test.raw)
+	if diags := test.resource.CreateContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%s: %+v", test.name, diags)
+	}
+
+	return d, meta
+}
+
+func TestRead_deletedIsRemovedFromStateAtOnce(t *testing.T) {
+	for _, test := range readTests() {
+		d, meta := createForReadTest(t, test)
+		id := d.Id()
+
+		if diags := test.resource.DeleteContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: %+v", test.name, diags)
+		}
+		d.SetId(id)
+
+		start := time.Now()
+		if diags := test.resource.ReadContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: %+v", test.name, diags)
+		}
+
+		if d.Id() != "" {
+			t.Errorf("%s: a deleted item should be removed from state", test.name)
+		}
+		if time.Since(start) > readTimeout/2 {
+			t.Errorf("%s: the read of a deleted item should not be retried", test.name)
+		}
+	}
+}
+
+func TestRead_errorsKeepTheResourceInState(t *testing.T) {
+	for _, test := range readTests() {
+		for _, statusCode := range []int{http.StatusForbidden, http.StatusUnauthorized, http.StatusBadRequest} {
+			d, meta := createForReadTest(t, test)
+			id := d.Id()
+
+			testEmulator.Fail(1, statusCode)
+			if diags := test.resource.ReadContext(context.Background(), d, meta); !diags.HasError() {
+				t.Errorf("%s: a %d should be an error", test.name, statusCode)
+			}
+
+			if d.Id() != id {
+				t.Errorf("%s: a %d should not remove the resource from state", test.name, statusCode)
+			}
+		}
+	}
+}
+
+func TestRead_newResourceIsRetriedWhileNotFound(t *testing.T) {
+	for _, test := range readTests() {
+		d, meta := createForReadTest(t, test)
+		d.MarkNewResource()
+
+		testEmulator.Fail(1, http.StatusNotFound)
+		if diags := test.resource.ReadContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: %+v", test.name, diags)
+		}
+
+		if d.Id() == "" {
+			t.Errorf("%s: a new item not visible yet should not be removed from state", test.name)
+		}
+	}
+}
+
+func TestRead_canceledContextStopsRetrying(t *testing.T) {
+	for _, test := range readTests() {
+		d, meta := createForReadTest(t, test)
+		id := d.Id()
+
+		if diags := test.resource.DeleteContext(context.Background(), d, meta); diags.HasError() {
+			t.Fatalf("%s: %+v", test.name, diags)
+		}
+		d.SetId(id)
+		d.MarkNewResource()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		start := time.Now()
+		if diags := test.resource.ReadContext(ctx, d, meta); !diags.HasError() {
+			t.Errorf("%s: a canceled read should be an error", test.name)
+		}
+		if time.Since(start) > readTimeout/2 {
+			t.Errorf("%s: a canceled read should not be retried", test.name)
+		}
+	}
+}
+
+// failingLocksConfigure configures clients whose lock and unlock requests fail with the given status code
+func failingLocksConfigure(statusCode int) interface{} {
+	return func(endpoint string) (*client.Client, error) {
+		cl, err := client.NewClient(endpoint, autorest.NullAuthorizer{})
+		if err != nil {
+			return nil, err
+		}
+
+		cl.Sender = autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			if strings.HasPrefix(r.URL.Path, "/locks/") {
+				return &http.Response{
+					StatusCode: statusCode,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+					Request:    r,
+				}, nil
+			}
+
+			return testEmulator.Client().Do(r)
+		})
+
+		return cl, nil
+	}
+}
+
+func TestCreate_lockConflictIsNotAnImport(t *testing.T) {
+	if testEmulator == nil {
+		t.Skip("failures can only be injected on the emulator")
+	}
+
+	for _, test := range readTests() {
+		test.raw["endpoint"] = endpointUnderTest
+		test.raw["label"] = uuid.New().String()
+		test.raw["locked"] = true
+
+		d := schema.TestResourceDataRaw(t, test.resource.Schema, test.raw)
+		diags := test.resource.CreateContext(context.Background(), d, failingLocksConfigure(http.StatusPreconditionFailed))
+		if !diags.HasError() {
+			t.Fatalf("%s: a failed lock should be an error", test.name)
+		}
+
+		if strings.Contains(diags[0].Summary, "imported") {
+			t.Errorf("%s: a failed lock should not ask for an import: %s", test.name, diags[0].Summary)
+		}
+	}
+}
+
+func TestWriteUnlocked_failedWriteLocksBack(t *testing.T) {
+	for _, relockErr := range []error{nil, client.PreconditionFailedError} {
+		var locks []string
+		l := lockable{
+			lock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+				locks = append(locks, "lock")
+				return client.KeyValueResponse{}, relockErr
+			},
+			unlock: func(options ...client.RequestOption) (client.KeyValueResponse, error) {
+				locks = append(locks, "unlock")
+				return client.KeyValueResponse{ETag: "unlocked"}, nil
+			},
+		}
+
+		err := writeUnlocked(l, "locked", true, false, func(etag string) (client.KeyValueResponse, error) {
+			return client.KeyValueResponse{}, client.ThrottledError
+		})
+		if !client.IsThrottled(err) {
+			t.Errorf("the error of the write should be returned, got %+v", err)
+		}
+		if len(locks) != 2 || locks[1] != "lock" {
+			t.Errorf("the key should be locked back after a failed write, got %v", locks)
+		}
+
+		diags := keyDiagnostics(err, "my-store.azconfig.io", "label", "key")
+		if leftUnlocked := len(diags) == 2 && strings.Contains(diags[1].Summary, "left unlocked"); leftUnlocked != (relockErr != nil) {
+			t.Errorf("a key which could not be locked back should be reported, got %+v", diags)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The IDs of the key-values and feature flags are the URL of their key-value, e.g.
+// https://my-store.azconfig.io/kv/MyApp%2FDb?label=Dev, the key being escaped as a single path segment
+// and the label being left out when there is none. Before version 1 of the schemas, they were
+// host/label/key and host/feature/label/name, which is ambiguous when the key or the label contains a slash.
+// The IDs of the akc_key_values resources are https://my-store.azconfig.io/kv?label=Dev&prefix=MyApp%3A and the ones
+// of the snapshots https://my-store.azconfig.io/snapshots/MySnapshot. The endpoint keeps its scheme and its port, so
+// that stores served over http or on another port, like a local emulator, are reached the same way after a refresh.
+// The legacy IDs only kept the host, the endpoint they give being then assumed to be served over https. The
+// akc_key_values and akc_snapshot resources came with version 1, so they have no legacy ID.
+
+func formatID(endpoint string, label string, key string) (string, error) {
+	base, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	id := base + "/kv/" + url.PathEscape(key)
+	if query := labelQuery(label); query != nil {
+		id += "?" + query.Encode()
+	}
+
+	return id, nil
+}
+
+func parseID(id string) (endpoint string, label string, key string, err error) {
+	u, err := url.Parse(id)
+	if err != nil || u.Scheme == "" || u.Host == "" || !strings.HasPrefix(u.EscapedPath(), "/kv/") {
+		return "", "", "", fmt.Errorf("invalid ID %s, expected %s", id, "<endpoint>/kv/<escaped key>[?label=<label>]")
+	}
+
+	key, err = url.PathUnescape(strings.TrimPrefix(u.EscapedPath(), "/kv/"))
+	if err != nil || key == "" {
+		return "", "", "", fmt.Errorf("invalid key in ID %s", id)
+	}
+
+	return u.Scheme + "://" + u.Host, labelOf(u.Query()), key, nil
+}
+
+func formatFeatureID(endpoint string, label string, name string) (string, error) {
+	return formatID(endpoint, label, client.FeaturePrefix+name)
+}
+
+func parseFeatureID(id string) (endpoint string, label string, name string, err error) {
+	endpoint, label, key, err := parseID(id)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if !strings.HasPrefix(key, client.FeaturePrefix) || key == client.FeaturePrefix {
+		return "", "", "", fmt.Errorf("invalid ID %s, the key is not the one of a feature flag", id)
+	}
+
+	return endpoint, label, strings.TrimPrefix(key, client.FeaturePrefix), nil
+}
+
+func formatPrefixID(endpoint string, label string, prefix string) (string, error) {
+	base, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := labelQuery(label)
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("prefix", prefix)
+
+	return base + "/kv?" + query.Encode(), nil
+}
+
+func parsePrefixID(id string) (endpoint string, label string, prefix string, err error) {
+	u, err := url.Parse(id)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.EscapedPath() != "/kv" {
+		return "", "", "", fmt.Errorf("invalid ID %s, expected %s", id, "<endpoint>/kv?[label=<label>&]prefix=<prefix>")
+	}
+
+	query := u.Query()
+
+	return u.Scheme + "://" + u.Host, labelOf(query), query.Get("prefix"), nil
+}
+
+func formatSnapshotID(endpoint string, name string) (string, error) {
+	base, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	return base + "/snapshots/" + url.PathEscape(name), nil
+}
+
+func parseSnapshotID(id string) (endpoint string, name string, err error) {
+	u, err := url.Parse(id)
+	if err != nil || u.Scheme == "" || u.Host == "" || !strings.HasPrefix(u.EscapedPath(), "/snapshots/") {
+		return "", "", fmt.Errorf("invalid ID %s, expected %s", id, "<endpoint>/snapshots/<escaped name>")
+	}
+
+	name, err = url.PathUnescape(strings.TrimPrefix(u.EscapedPath(), "/snapshots/"))
+	if err != nil || name == "" {
+		return "", "", fmt.Errorf("invalid name in ID %s", id)
+	}
+
+	return u.Scheme + "://" + u.Host, name, nil
+}
+
+// normalizeEndpoint reduces the endpoint to its scheme and its host in lower case, without trailing slash nor
+// the default port of the scheme
+func normalizeEndpoint(endpoint string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("unable to parse the given endpoint %s", endpoint)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	port := u.Port()
+	if port != "" && !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		host += ":" + port
+	}
+
+	return scheme + "://" + host, nil
+}
+
+// suppressEndpointDiff ignores the differences between two spellings of the same endpoint
+func suppressEndpointDiff(k string, old string, new string, d *schema.ResourceData) bool {
+	normalizedOld, err := normalizeEndpoint(old)
+	if err != nil {
+		return false
+	}
+
+	normalizedNew, err := normalizeEndpoint(new)
+	if err != nil {
+		return false
+	}
+
+	return normalizedOld == normalizedNew
+}
+
+func labelQuery(label string) url.Values {
+	if label == client.LabelNone || label == "" {
+		return nil
+	}
+
+	return url.Values{"label": {label}}
+}
+
+func labelOf(query url.Values) string {
+	if label := query.Get("label"); label != "" {
+		return label
+	}
+
+	return client.LabelNone
+}
+
+// isLegacyID tells whether the ID has the format used before version 1 of the schemas, which has no scheme
+func isLegacyID(id string) bool {
+	return !strings.Contains(id, "://")
+}
+
+// parseLegacyID parses a host/label/key ID, the key being everything after the second slash
+func parseLegacyID(id string) (endpoint string, label string, key string, err error) {
+	split := strings.SplitN(id, "/", 3)
+	if len(split) != 3 {
+		return "", "", "", fmt.Errorf("invalid ID %s, expected %s", id, "<host>/<label>/<key>")
+	}
+
+	return "https://" + split[0], split[1], split[2], nil
+}
+
+// parseLegacyFeatureID parses a host/feature/label/name ID, the name being everything after the third slash
+func parseLegacyFeatureID(id string) (endpoint string, label string, name string, err error) {
+	split := strings.SplitN(id, "/", 4)
+	if len(split) != 4 || split[1] != "feature" {
+		return "", "", "", fmt.Errorf("invalid ID %s, expected %s", id, "<host>/feature/<label>/<name>")
+	}
+
+	return "https://" + split[0], split[2], split[3], nil
+}
+
+// withoutLabel adapts the ID functions of the resources having no label to importState
+func withoutLabel(parse func(id string) (string, string, error)) func(id string) (string, string, string, error) {
+	return func(id string) (string, string, string, error) {
+		endpoint, name, err := parse(id)
+
+		return endpoint, "", name, err
+	}
+}
+
+func formatWithoutLabel(format func(endpoint string, name string) (string, error)) func(endpoint string, label string, name string) (string, error) {
+	return func(endpoint string, label string, name string) (string, error) {
+		return format(endpoint, name)
+	}
+}
+
+// importState makes an importer accepting the IDs in the current format as well as in the legacy one, if any,
+// which it converts
+func importState(parse func(id string) (string, string, string, error), parseLegacy func(id string) (string, string, string, error), format func(endpoint string, label string, key string) (string, error)) *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			parseID := parse
+			if parseLegacy != nil && isLegacyID(d.Id()) {
+				parseID = parseLegacy
+			}
+
+			endpoint, label, key, err := parseID(d.Id())
+			if err != nil {
+				return nil, err
+			}
+
+			id, err := format(endpoint, label, key)
+			if err != nil {
+				return nil, err
+			}
+
+			d.SetId(id)
+
+			return []*schema.ResourceData{d}, nil
+		},
+	}
+}
+
+// withIDUpgrade sets the schema version of a resource whose ID format changed in version 1, v0 being its schema
+// before. The upgrade rebuilds the ID from the endpoint, the label and the attribute holding the key.
+func withIDUpgrade(r *schema.Resource, v0 *schema.Resource, keyAttribute string, parseLegacy func(id string) (string, string, string, error), format func(endpoint string, label string, key string) (string, error)) *schema.Resource {
+	r.SchemaVersion = 1
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    v0.CoreConfigSchema().ImpliedType(),
+			Upgrade: func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+				id, _ := rawState["id"].(string)
+				endpoint, label, key, err := parseLegacy(id)
+
+				if value, ok := rawState["endpoint"].(string); ok && value != "" {
+					endpoint = value
+				}
+				if value, ok := rawState["label"].(string); ok && value != "" {
+					label = value
+				}
+				if value, ok := rawState[keyAttribute].(string); ok && value != "" {
+					key, err = value, nil
+				}
+
+				if err != nil {
+					return nil, fmt.Errorf("unable to upgrade the ID %s: %+v", id, err)
+				}
+
+				if rawState["id"], err = format(endpoint, label, key); err != nil {
+					return nil, err
+				}
+
+				return rawState, nil
+			},
+		},
+	}
+
+	return r
+}
@@ -0,0 +1,241 @@
+package akc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestID_roundTrip(t *testing.T) {
+	keys := []string{"MyApp/Db/Conn", "a?b#c", "50%", "a+b c", "日本", "MyApp:Key", ".appconfig.featureflag/x"}
+	labels := []string{client.LabelNone, "Dev", "eu/west", "a,b*", "50%", "%00", "a&label=b"}
+
+	for _, key := range keys {
+		for _, label := range labels {
+			id, err := formatID("https://my-store.azconfig.io", label, key)
+			if err != nil {
+				t.Fatalf("formatID(%q, %q): %+v", label, key, err)
+			}
+
+			endpoint, parsedLabel, parsedKey, err := parseID(id)
+			if err != nil {
+				t.Fatalf("parseID(%q): %+v", id, err)
+			}
+			if endpoint != "https://my-store.azconfig.io" || parsedLabel != label || parsedKey != key {
+				t.Errorf("parseID(%q) = %q, %q, %q, expected %q, %q", id, endpoint, parsedLabel, parsedKey, label, key)
+			}
+		}
+	}
+}
+
+func TestID_format(t *testing.T) {
+	id, _ := formatID("https://my-store.azconfig.io", "Dev", "MyApp/Db")
+	if id != "https://my-store.azconfig.io/kv/MyApp%2FDb?label=Dev" {
+		t.Errorf("unexpected ID %s", id)
+	}
+
+	id, _ = formatID("https://my-store.azconfig.io", client.LabelNone, "MyApp")
+	if id != "https://my-store.azconfig.io/kv/MyApp" {
+		t.Errorf("unexpected ID %s", id)
+	}
+
+	id, _ = formatFeatureID("https://my-store.azconfig.io", "Dev", "Beta")
+	if id != "https://my-store.azconfig.io/kv/.appconfig.featureflag%2FBeta?label=Dev" {
+		t.Errorf("unexpected ID %s", id)
+	}
+}
+
+func TestFeatureID_roundTrip(t *testing.T) {
+	id, _ := formatFeatureID("https://my-store.azconfig.io", "eu/west", "Beta/Dark")
+
+	endpoint, label, name, err := parseFeatureID(id)
+	if err != nil || endpoint != "https://my-store.azconfig.io" || label != "eu/west" || name != "Beta/Dark" {
+		t.Errorf("parseFeatureID(%q) = %q, %q, %q, %+v", id, endpoint, label, name, err)
+	}
+
+	keyID, _ := formatID("https://my-store.azconfig.io", "Dev", "Beta")
+	if _, _, _, err := parseFeatureID(keyID); err == nil {
+		t.Errorf("parseFeatureID(%q) should fail", keyID)
+	}
+}
+
+func TestPrefixID_roundTrip(t *testing.T) {
+	id, _ := formatPrefixID("https://my-store.azconfig.io", "eu/west", "MyApp/")
+
+	endpoint, label, prefix, err := parsePrefixID(id)
+	if err != nil || endpoint != "https://my-store.azconfig.io" || label != "eu/west" || prefix != "MyApp/" {
+		t.Errorf("parsePrefixID(%q) = %q, %q, %q, %+v", id, endpoint, label, prefix, err)
+	}
+}
+
+func TestID_invalid(t *testing.T) {
+	for _, id := range []string{"", "my-store.azconfig.io/Dev/key", "https://my-store.azconfig.io/kv/", "https://my-store.azconfig.io/other/key"} {
+		if _, _, _, err := parseID(id); err == nil {
+			t.Errorf("parseID(%q) should fail", id)
+		}
+	}
+}
+
+func TestImportState_legacyID(t *testing.T) {
+	tests := []struct {
+		resource *schema.Resource
+		legacyID string
+		id       string
+	}{
+		{resourceKeyValue(), "my-store.azconfig.io/Dev/MyApp/Db", "https://my-store.azconfig.io/kv/MyApp%2FDb?label=Dev"},
+		{resourceKeySecret(), "my-store.azconfig.io/%00/Secret", "https://my-store.azconfig.io/kv/Secret"},
+		{resourceFeature(), "my-store.azconfig.io/feature/Dev/Beta", "https://my-store.azconfig.io/kv/.appconfig.featureflag%2FBeta?label=Dev"},
+	}
+
+	for _, test := range tests {
+		for _, id := range []string{test.legacyID, test.id} {
+			d := test.resource.Data(nil)
+			d.SetId(id)
+
+			result, err := test.resource.Importer.StateContext(context.Background(), d, nil)
+			if err != nil {
+				t.Fatalf("import %q: %+v", id, err)
+			}
+			if result[0].Id() != test.id {
+				t.Errorf("import %q gave %q, expected %q", id, result[0].Id(), test.id)
+			}
+		}
+	}
+}
+
+func TestStateUpgrade_v0(t *testing.T) {
+	tests := []struct {
+		resource *schema.Resource
+		state    map[string]interface{}
+		id       string
+	}{
+		{
+			resourceKeyValue(),
+			map[string]interface{}{"id": "my-store.azconfig.io/Dev/MyApp", "endpoint": "https://my-store.azconfig.io", "label": "Dev", "key": "MyApp/Db/Conn"},
+			"https://my-store.azconfig.io/kv/MyApp%2FDb%2FConn?label=Dev",
+		},
+		{
+			resourceFeature(),
+			map[string]interface{}{"id": "my-store.azconfig.io/feature/%00/Beta", "endpoint": "https://my-store.azconfig.io", "label": "%00", "name": "Beta"},
+			"https://my-store.azconfig.io/kv/.appconfig.featureflag%2FBeta",
+		},
+		{
+			resourceKeySecret(),
+			map[string]interface{}{"id": "my-store.azconfig.io/Dev/Secret"},
+			"https://my-store.azconfig.io/kv/Secret?label=Dev",
+		},
+		{
+			resourceKeyValue(),
+			map[string]interface{}{"id": "localhost:8483/Dev/MyApp", "endpoint": "http://LocalHost:8483/", "label": "Dev", "key": "MyApp"},
+			"http://localhost:8483/kv/MyApp?label=Dev",
+		},
+	}
+
+	for _, test := range tests {
+		if test.resource.SchemaVersion != 1 || len(test.resource.StateUpgraders) != 1 {
+			t.Fatalf("expected a state upgrader from version 0")
+		}
+		if _, ok := test.resource.StateUpgraders[0].Type.AttributeTypes()["etag"]; ok {
+			t.Errorf("the state upgrader should declare the schema of version 0, not the current one")
+		}
+
+		state, err := test.resource.StateUpgraders[0].Upgrade(context.Background(), test.state, nil)
+		if err != nil {
+			t.Fatalf("upgrade %v: %+v", test.state, err)
+		}
+		if state["id"] != test.id {
+			t.Errorf("upgrade %v gave %q, expected %q", test.state, state["id"], test.id)
+		}
+	}
+}
+
+func TestImportState_noLegacyID(t *testing.T) {
+	tests := []struct {
+		resource *schema.Resource
+		legacyID string
+	}{
+		{resourceKeyValues(), "my-store.azconfig.io/Dev/MyApp:"},
+		{resourceSnapshot(), "my-store.azconfig.io/snapshot/Release"},
+	}
+
+	for _, test := range tests {
+		if test.resource.SchemaVersion != 0 || len(test.resource.StateUpgraders) != 0 {
+			t.Errorf("%q: a resource which never had a legacy ID should not be upgraded", test.legacyID)
+		}
+
+		d := test.resource.Data(nil)
+		d.SetId(test.legacyID)
+		if _, err := test.resource.Importer.StateContext(context.Background(), d, nil); err == nil {
+			t.Errorf("import %q should fail", test.legacyID)
+		}
+	}
+}
+
+func TestID_keepsSchemeAndPort(t *testing.T) {
+	for _, endpoint := range []string{"http://localhost:8483", "https://my-store.azconfig.io:8443", "http://127.0.0.1:80"} {
+		id, err := formatID(endpoint, "Dev", "MyApp")
+		if err != nil {
+			t.Fatalf("formatID(%q): %+v", endpoint, err)
+		}
+
+		parsed, _, _, err := parseID(id)
+		expected, _ := normalizeEndpoint(endpoint)
+		if err != nil || parsed != expected {
+			t.Errorf("parseID(%q) gave the endpoint %q, expected %q", id, parsed, expected)
+		}
+
+		id, _ = formatSnapshotID(endpoint, "Release")
+		if parsed, _, err = parseSnapshotID(id); err != nil || parsed != expected {
+			t.Errorf("parseSnapshotID(%q) gave the endpoint %q, expected %q", id, parsed, expected)
+		}
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://my-store.azconfig.io":       "https://my-store.azconfig.io",
+		"https://My-Store.AzConfig.io/":      "https://my-store.azconfig.io",
+		"HTTPS://my-store.azconfig.io:443/":  "https://my-store.azconfig.io",
+		"http://localhost:8483/":             "http://localhost:8483",
+		"http://localhost:80":                "http://localhost",
+		"http://localhost:443":               "http://localhost:443",
+		"https://[::1]:8443":                 "https://[::1]:8443",
+		" https://my-store.azconfig.io/kv/ ": "https://my-store.azconfig.io",
+	}
+
+	for endpoint, expected := range tests {
+		if normalized, err := normalizeEndpoint(endpoint); err != nil || normalized != expected {
+			t.Errorf("normalizeEndpoint(%q) = %q, %+v, expected %q", endpoint, normalized, err, expected)
+		}
+	}
+
+	for _, endpoint := range []string{"", "my-store.azconfig.io", "://my-store.azconfig.io"} {
+		if _, err := normalizeEndpoint(endpoint); err == nil {
+			t.Errorf("normalizeEndpoint(%q) should fail", endpoint)
+		}
+	}
+}
+
+func TestSuppressEndpointDiff(t *testing.T) {
+	if !suppressEndpointDiff("endpoint", "https://my-store.azconfig.io", "https://My-Store.azconfig.io/", nil) {
+		t.Errorf("the same endpoint spelled differently should not be a diff")
+	}
+
+	for _, other := range []string{"http://my-store.azconfig.io", "https://my-store.azconfig.io:8443", "https://other.azconfig.io", ""} {
+		if suppressEndpointDiff("endpoint", "https://my-store.azconfig.io", other, nil) {
+			t.Errorf("%q should be a diff", other)
+		}
+	}
+}
+
+func TestSameHost(t *testing.T) {
+	if !sameHost("https://My-Store.azconfig.io/", "https://my-store.azconfig.io:443") {
+		t.Errorf("the same store spelled differently should be the same host")
+	}
+
+	if sameHost("http://localhost:8483", "http://localhost:8484") {
+		t.Errorf("the ports differ")
+	}
+}
@@ -1,72 +1,176 @@
-package akc
-
-import (
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
-
-func Provider() *schema.Provider {
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{
-			"client_id": {
-				Type:        schema.TypeString,
-				Description: "Azure AD Client Id",
-				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_ID", nil),
-			},
-			"client_secret": {
-				Type:        schema.TypeString,
-				Description: "Azure AD Client Secret",
-				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_SECRET", nil),
-			},
-			"tenant_id": {
-				Type:        schema.TypeString,
-				Description: "Azure AD Tenant ID",
-				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc("ARM_TENANT_ID", nil),
-			},
-			"msi": {
-				Type:        schema.TypeBool,
-				Description: "Use msi if available, will fail if not in a MSI context",
-				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ARM_USE_MSI", false),
-			},
-		},
-		ResourcesMap: map[string]*schema.Resource{
-			"akc_key_value":  resourceKeyValue(),
-			"akc_key_secret": resourceKeySecret(),
-			"akc_feature":    resourceFeature(),
-		},
-		DataSourcesMap: map[string]*schema.Resource{
-			"akc_key_value":  dataSourceKeyValue(),
-			"akc_key_secret": dataSourceKeySecret(),
-			"akc_feature":    dataSourceFeature(),
-		},
-		ConfigureFunc: providerConfigure,
-	}
-}
-
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	if d.Get("msi").(bool) {
-		return func(endpoint string) (*client.Client, error) {
-			return client.NewClientMsi(endpoint)
-		}, nil
-	}
-
-	clientId := d.Get("client_id").(string)
-	clientSecret := d.Get("client_secret").(string)
-	tenantId := d.Get("tenant_id").(string)
-
-	if (clientId != "") && (clientSecret != "") && (tenantId != "") {
-		return func(endpoint string) (*client.Client, error) {
-			return client.NewClientCreds(endpoint, clientId, clientSecret, tenantId)
-		}, nil
-	}
-
-	return func(endpoint string) (*client.Client, error) {
-		return client.NewClientCli(endpoint)
-	}, nil
-}
+package akc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func Provider() *schema.Provider {
+	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"client_id": {
+				Type:        schema.TypeString,
+				Description: "Azure AD Client Id",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_ID", nil),
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Description: "Azure AD Client Secret",
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_CLIENT_SECRET", nil),
+			},
+			"tenant_id": {
+				Type:        schema.TypeString,
+				Description: "Azure AD Tenant ID",
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_TENANT_ID", nil),
+			},
+			"connection_string": {
+				Type:        schema.TypeString,
+				Description: "App Configuration access key connection string (Endpoint=...;Id=...;Secret=...)",
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("AKC_CONNECTION_STRING", nil),
+			},
+			"msi": {
+				Type:        schema.TypeBool,
+				Description: "Use msi if available, will fail if not in a MSI context",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ARM_USE_MSI", false),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Description:  "Number of times a throttled or transiently failed request is retried, 0 disabling retries",
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("AKC_MAX_RETRIES", client.DefaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Description:  "Longest wait between two attempts of a request, in seconds",
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("AKC_RETRY_MAX_WAIT", int(client.DefaultRetryMaxWait/time.Second)),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			"akc_key_value":  resourceKeyValue(),
+			"akc_key_secret": resourceKeySecret(),
+			"akc_feature":    resourceFeature(),
+			"akc_key_values": resourceKeyValues(),
+			"akc_snapshot":   resourceSnapshot(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"akc_key_value":          dataSourceKeyValue(),
+			"akc_key_secret":         dataSourceKeySecret(),
+			"akc_feature":            dataSourceFeature(),
+			"akc_feature_evaluation": dataSourceFeatureEvaluation(),
+			"akc_features":           dataSourceFeatures(),
+			"akc_labels":             dataSourceLabels(),
+			"akc_keys":               dataSourceKeys(),
+			"akc_key_values":         dataSourceKeyValues(),
+			"akc_key_revisions":      dataSourceKeyRevisions(),
+			"akc_snapshot":           dataSourceSnapshot(),
+		},
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	clientBuilder, err := configureClientBuilder(d)
+	if err != nil {
+		return nil, errorDiagnostics("error configuring the App Configuration clients", err)
+	}
+
+	maxRetries := d.Get("max_retries").(int)
+	retryMaxWait := time.Duration(d.Get("retry_max_wait").(int)) * time.Second
+
+	return func(endpoint string) (*client.Client, error) {
+		cl, err := clientBuilder(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		cl.MaxRetries = maxRetries
+		cl.RetryMaxWait = retryMaxWait
+
+		return cl, nil
+	}, nil
+}
+
+// configureClientBuilder chooses the way the clients authenticate
+func configureClientBuilder(d *schema.ResourceData) (func(endpoint string) (*client.Client, error), error) {
+	if connectionString := d.Get("connection_string").(string); connectionString != "" {
+		cs, err := client.ParseConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(endpoint string) (*client.Client, error) {
+			if !sameHost(endpoint, cs.Endpoint) {
+				return nil, fmt.Errorf("the connection string grants access to %s, not to %s", cs.Endpoint, endpoint)
+			}
+
+			return client.NewClientAccessKey(endpoint, cs.ID, cs.Secret)
+		}, nil
+	}
+
+	if d.Get("msi").(bool) {
+		return func(endpoint string) (*client.Client, error) {
+			return client.NewClientMsi(endpoint)
+		}, nil
+	}
+
+	clientId := d.Get("client_id").(string)
+	clientSecret := d.Get("client_secret").(string)
+	tenantId := d.Get("tenant_id").(string)
+
+	if (clientId != "") && (clientSecret != "") && (tenantId != "") {
+		return func(endpoint string) (*client.Client, error) {
+			return client.NewClientCreds(endpoint, clientId, clientSecret, tenantId)
+		}, nil
+	}
+
+	return func(endpoint string) (*client.Client, error) {
+		return client.NewClientCli(endpoint)
+	}, nil
+}
+
+// sameHost tells whether both endpoints point to the same App Configuration store, on the same port
+func sameHost(endpoint string, other string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	o, err := url.Parse(other)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Hostname(), o.Hostname()) && effectivePort(u) == effectivePort(o)
+}
+
+// effectivePort is the port of the URL, or the default one of its scheme
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	if strings.EqualFold(u.Scheme, "http") {
+		return "80"
+	}
+
+	return "443"
+}
@@ -1,20 +1,117 @@
-package akc
-
-import (
-	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
-
-var testProviders = map[string]*schema.Provider{
-	"akc": Provider(),
-}
-
-const appConfigHost = "testlg.azconfig.io"
-const endpointUnderTest = "https://" + appConfigHost
-
-func TestProvider(t *testing.T) {
-	if err := Provider().InternalValidate(); err != nil {
-		t.Fatalf("err: %s", err)
-	}
-}
+package akc
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/arkiaconsulting/terraform-provider-akc/emulator"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var testProviders = map[string]*schema.Provider{
+	"akc": Provider(),
+}
+
+// endpointUnderTest is the store the acceptance tests run against: an in-process emulator, unless AKC_TEST_ENDPOINT
+// points to another store, in which case AKC_CONNECTION_STRING or the ARM_* variables must hold credentials allowed to use it
+var endpointUnderTest string
+
+var testEmulator *emulator.Server
+
+func TestMain(m *testing.M) {
+	endpointUnderTest = os.Getenv("AKC_TEST_ENDPOINT")
+	if endpointUnderTest == "" {
+		testEmulator = emulator.NewServer()
+		endpointUnderTest = testEmulator.URL
+		testProviders["akc"].ConfigureContextFunc = emulatorConfigure
+	}
+
+	code := m.Run()
+
+	if testEmulator != nil {
+		testEmulator.Close()
+	}
+
+	os.Exit(code)
+}
+
+func emulatorConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	return func(endpoint string) (*client.Client, error) {
+		cl, err := client.NewClient(endpoint, autorest.NullAuthorizer{})
+		if err != nil {
+			return nil, err
+		}
+
+		cl.Sender = testEmulator.Client()
+
+		return cl, nil
+	}, nil
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestProvider_retrySettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"connection_string": "Endpoint=https://my-store.azconfig.io;Id=my-id;Secret=c2VjcmV0",
+		"max_retries":       2,
+		"retry_max_wait":    7,
+	})
+
+	meta, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %+v", diags)
+	}
+
+	cl, err := meta.(func(endpoint string) (*client.Client, error))("https://my-store.azconfig.io")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if cl.MaxRetries != 2 || cl.RetryMaxWait != 7*time.Second {
+		t.Errorf("unexpected retry settings %d, %s", cl.MaxRetries, cl.RetryMaxWait)
+	}
+}
+
+func TestProvider_invalidConnectionString(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"connection_string": "Endpoint=https://my-store.azconfig.io",
+	})
+
+	_, diags := providerConfigure(context.Background(), d)
+	if !diags.HasError() {
+		t.Fatal("an invalid connection string should be an error")
+	}
+	if diags[0].Summary == "" || diags[0].Detail == "" {
+		t.Errorf("the diagnostic should have a summary and a detail, got %+v", diags[0])
+	}
+}
+
+func TestProvider_readTimeoutsOutlastRetries(t *testing.T) {
+	p := Provider()
+	resources := map[string]*schema.Resource{}
+	for name, r := range p.ResourcesMap {
+		resources[name] = r
+	}
+	for name, r := range p.DataSourcesMap {
+		resources["data."+name] = r
+	}
+
+	for name, r := range resources {
+		if r.Timeouts == nil || r.Timeouts.Read == nil {
+			continue
+		}
+
+		if *r.Timeouts.Read < time.Duration(client.DefaultMaxRetries)*client.DefaultRetryMaxWait {
+			t.Errorf("%s: the read timeout %s would cut the retries of the client short", name, *r.Timeouts.Read)
+		}
+	}
+}
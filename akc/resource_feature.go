@@ -1,203 +1,301 @@
-package akc
-
-import (
-	"fmt"
-	"log"
-	"net/url"
-	"strings"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
-
-func resourceFeature() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceFeatureCreate,
-		Read:   resourceFeatureRead,
-		Update: resourceFeatureUpdate,
-		Delete: resourceFeatureDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"endpoint": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"label": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-				Default:  client.LabelNone,
-			},
-			"enabled": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-		},
-		Timeouts: &schema.ResourceTimeout{
-			Read: schema.DefaultTimeout(readTimeout),
-		},
-	}
-}
-
-func resourceFeatureCreate(d *schema.ResourceData, meta interface{}) error {
-
-	endpoint := d.Get("endpoint").(string)
-	name := d.Get("name").(string)
-	label := d.Get("label").(string)
-	description := d.Get("description").(string)
-	enabled := d.Get("enabled").(bool)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	if d.IsNewResource() {
-		err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-			_, err = cl.GetFeature(label, name)
-
-			if err != nil {
-				if client.IsNotFound(err) {
-					log.Printf("[INFO] retrying to get feature '%s/%s' because: %s", label, name, err)
-
-					return resource.RetryableError(err)
-				}
-
-				return resource.NonRetryableError(err)
-			}
-
-			return nil
-		})
-
-		if err == nil {
-			return fmt.Errorf("the resource needs to be imported: %s", d.Id())
-		}
-	}
-
-	_, err = cl.SetFeature(name, label, enabled, description)
-	if err != nil {
-		return err
-	}
-
-	id, err := formatFeatureID(endpoint, label, name)
-	if err != nil {
-		return err
-	}
-
-	d.SetId(id)
-
-	return resourceFeatureRead(d, meta)
-}
-
-func resourceFeatureRead(d *schema.ResourceData, meta interface{}) error {
-	endpoint, label, name := parseFeatureID(d.Id())
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	var feature client.FeatureResponse
-	err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-		feature, err = cl.GetFeature(label, name)
-
-		if err != nil {
-			if client.IsNotFound(err) {
-				log.Printf("[INFO] retrying to get feature '%s:%s' because: %s", label, name, err)
-
-				return resource.RetryableError(err)
-			}
-
-			return resource.NonRetryableError(err)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		log.Printf("[INFO] KV not found, removing from state: %s/%s/%s", endpoint, label, name)
-		d.SetId("")
-		return nil
-	}
-
-	d.Set("endpoint", endpoint)
-	d.Set("name", name)
-	d.Set("label", label)
-	d.Set("description", feature.Description)
-	d.Set("enabled", feature.Enabled)
-
-	log.Printf("[INFO] KV has been fetched %s/%s/%s", endpoint, label, name)
-
-	return nil
-}
-
-func resourceFeatureUpdate(d *schema.ResourceData, meta interface{}) error {
-
-	endpoint, label, name := parseFeatureID(d.Id())
-	description := d.Get("description").(string)
-	enabled := d.Get("enabled").(bool)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	_, err = cl.SetFeature(name, label, enabled, description)
-	if err != nil {
-		return err
-	}
-
-	return resourceFeatureRead(d, meta)
-}
-
-func resourceFeatureDelete(d *schema.ResourceData, meta interface{}) error {
-	endpoint, label, name := parseFeatureID(d.Id())
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	_, err = cl.DeleteFeature(label, name)
-	if err != nil {
-		return err
-	}
-
-	d.SetId("")
-
-	return nil
-}
-
-func formatFeatureID(endpoint string, label string, name string) (string, error) {
-	url, err := url.Parse(endpoint)
-	if err != nil {
-		return "", fmt.Errorf("unable to parse the given endpoint %s", endpoint)
-	}
-
-	host := url.Host
-
-	return fmt.Sprintf("%s/feature/%s/%s", host, label, name), nil
-}
-
-func parseFeatureID(id string) (endpoint string, label string, name string) {
-	split := strings.Split(id, "/")
-
-	endpoint = fmt.Sprintf("https://%s", split[0])
-	label = split[2]
-	name = split[3]
-
-	return endpoint, label, name
-}
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceFeature() *schema.Resource {
+	return withIDUpgrade(&schema.Resource{
+		CreateContext: resourceFeatureCreate,
+		ReadContext:   resourceFeatureRead,
+		UpdateContext: resourceFeatureUpdate,
+		DeleteContext: resourceFeatureDelete,
+		CustomizeDiff: resourceFeatureCustomizeDiff,
+		Importer:      importState(parseFeatureID, parseLegacyFeatureID, formatFeatureID),
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEndpointDiff,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  client.LabelNone,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"client_filter": featureFilterSchema(),
+			"requirement_type": {
+				Type:         schema.TypeString,
+				Description:  "Whether Any or All of the client filters must match for the feature to be on",
+				Optional:     true,
+				Default:      client.FeatureRequirementAny,
+				ValidateFunc: validation.StringInSlice([]string{client.FeatureRequirementAny, client.FeatureRequirementAll}, false),
+			},
+			"variant":    featureVariantSchema(),
+			"allocation": featureAllocationSchema(),
+			"telemetry":  featureTelemetrySchema(),
+			"locked": {
+				Type:        schema.TypeBool,
+				Description: "Make the key read-only, Terraform unlocks it temporarily when it needs to change it",
+				Optional:    true,
+				Default:     false,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}, resourceFeatureV0(), "name", parseLegacyFeatureID, formatFeatureID)
+}
+
+// resourceFeatureV0 is the schema of the feature flags before version 1, when their ID was host/feature/label/name
+func resourceFeatureV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  client.LabelNone,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceFeatureCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	endpoint := d.Get("endpoint").(string)
+	name := d.Get("name").(string)
+	label := d.Get("label").(string)
+
+	feature, err := expandFeature(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var createErr error
+	err = writeUnlocked(featureLock(ctx, cl, label, name), "", false, d.Get("locked").(bool), func(string) (client.KeyValueResponse, error) {
+		kv, err := cl.SetFeatureFlagContext(ctx, name, label, feature, client.IfNoneMatch("*"))
+		createErr = err
+
+		return kv, err
+	})
+	if client.IsPreconditionFailed(createErr) {
+		return errorDiagnostics(fmt.Sprintf("the resource needs to be imported: %s", "akc_feature"), createErr)
+	}
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, name)
+	}
+
+	id, err := formatFeatureID(endpoint, label, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	return resourceFeatureRead(ctx, d, meta)
+}
+
+func resourceFeatureRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint, label, name, err := parseFeatureID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var feature client.FeatureResponse
+	err = readNewResource(ctx, d, fmt.Sprintf("feature '%s/%s'", label, name), func() (err error) {
+		feature, err = cl.GetFeatureContext(ctx, label, name)
+
+		return err
+	})
+
+	if client.IsNotFound(err) && !d.IsNewResource() {
+		log.Printf("[INFO] feature not found, removing from state: %s/%s/%s", endpoint, label, name)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting App Configuration feature %s/%s", label, name), err)
+	}
+
+	d.Set("endpoint", endpoint)
+	d.Set("name", name)
+	d.Set("label", label)
+	d.Set("description", feature.Description)
+	d.Set("enabled", feature.Enabled)
+	if err := d.Set("client_filter", flattenFeatureFilters(feature.Conditions.ClientFilters, d.Get("client_filter").([]interface{}))); err != nil {
+		return errorDiagnostics("error setting client_filter", err)
+	}
+	d.Set("requirement_type", requirementTypeOrAny(feature.Conditions.RequirementType))
+	if err := d.Set("variant", flattenFeatureVariants(feature.Variants)); err != nil {
+		return errorDiagnostics("error setting variant", err)
+	}
+	if err := d.Set("allocation", flattenFeatureAllocation(feature.Allocation)); err != nil {
+		return errorDiagnostics("error setting allocation", err)
+	}
+	if err := d.Set("telemetry", flattenFeatureTelemetry(feature.Telemetry)); err != nil {
+		return errorDiagnostics("error setting telemetry", err)
+	}
+	d.Set("locked", feature.Locked)
+	d.Set("etag", feature.ETag)
+
+	log.Printf("[INFO] KV has been fetched %s/%s/%s", endpoint, label, name)
+
+	return nil
+}
+
+func resourceFeatureUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	endpoint, label, name, err := parseFeatureID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	etag := d.Get("etag").(string)
+
+	feature, err := expandFeature(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var write func(etag string) (client.KeyValueResponse, error)
+	if d.HasChanges("enabled", "description", "client_filter", "requirement_type", "variant", "allocation", "telemetry") {
+		// the flag is read back so that the fields this resource does not manage are written untouched, the
+		// etag condition failing if it changed in between
+		write = func(etag string) (client.KeyValueResponse, error) {
+			current, err := cl.GetFeatureContext(ctx, label, name)
+			if err != nil {
+				return client.KeyValueResponse{}, err
+			}
+
+			feature.Raw = current.Raw
+
+			return cl.SetFeatureFlagContext(ctx, name, label, feature, client.IfMatch(etag))
+		}
+	}
+
+	wasLocked, lock := d.GetChange("locked")
+	err = writeUnlocked(featureLock(ctx, cl, label, name), etag, wasLocked.(bool), lock.(bool), write)
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, name)
+	}
+
+	return resourceFeatureRead(ctx, d, meta)
+}
+
+func resourceFeatureDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint, label, name, err := parseFeatureID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	err = writeUnlocked(featureLock(ctx, cl, label, name), d.Get("etag").(string), d.Get("locked").(bool), false, func(etag string) (client.KeyValueResponse, error) {
+		_, err := cl.DeleteFeatureContext(ctx, label, name, client.IfMatch(etag))
+		return client.KeyValueResponse{}, err
+	})
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, name)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func expandFeature(d *schema.ResourceData) (client.Feature, error) {
+	filters, err := expandFeatureFilters(d.Get("client_filter").([]interface{}))
+	if err != nil {
+		return client.Feature{}, err
+	}
+
+	variants, err := expandFeatureVariants(d.Get("variant").([]interface{}))
+	if err != nil {
+		return client.Feature{}, err
+	}
+
+	feature := client.Feature{
+		Description: d.Get("description").(string),
+		Enabled:     d.Get("enabled").(bool),
+		Conditions: client.FeatureConditions{
+			ClientFilters:   filters,
+			RequirementType: d.Get("requirement_type").(string),
+		},
+		Variants:   variants,
+		Allocation: expandFeatureAllocation(d.Get("allocation").([]interface{})),
+		Telemetry:  expandFeatureTelemetry(d.Get("telemetry").([]interface{})),
+	}
+
+	return feature, feature.Validate()
+}
+
+// requirementTypeOrAny returns the requirement type of a feature flag, which defaults to Any when missing
+func requirementTypeOrAny(requirementType string) string {
+	if requirementType == "" {
+		return client.FeatureRequirementAny
+	}
+
+	return requirementType
+}
@@ -1,156 +1,263 @@
-package akc
-
-import (
-	"strconv"
-	"testing"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-)
-
-func TestAccResourceFeature_create(t *testing.T) {
-	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
-	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	enabled := randBool()
-
-	var kv client.FeatureResponse
-
-	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { preCheck(t) },
-		Providers:    testProviders,
-		CheckDestroy: testCheckFeatureDestroy,
-		Steps: []resource.TestStep{
-			{
-				Config: buildLabeledFeature(name, label, description, enabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
-				),
-			},
-			{
-				ResourceName:      "akc_feature.test",
-				ImportState:       true,
-				ImportStateVerify: true,
-			},
-		},
-	})
-}
-
-func TestAccResourceFeatureNoLabel_createNoLabel(t *testing.T) {
-	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	enabled := randBool()
-
-	var kv client.FeatureResponse
-
-	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { preCheck(t) },
-		Providers:    testProviders,
-		CheckDestroy: testCheckFeatureDestroy,
-		Steps: []resource.TestStep{
-			{
-				Config: buildFeature(name, description, enabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
-				),
-			},
-			{
-				ResourceName:      "akc_feature.test",
-				ImportState:       true,
-				ImportStateVerify: true,
-			},
-		},
-	})
-}
-
-func TestAccResourceFeature_updateNoLabel(t *testing.T) {
-	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	newDescription := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	enabled := randBool()
-	newEnabled := !enabled
-
-	var kv client.FeatureResponse
-
-	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { preCheck(t) },
-		Providers:    testProviders,
-		CheckDestroy: testCheckFeatureDestroy,
-		Steps: []resource.TestStep{
-			{
-				Config: buildFeature(name, description, enabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
-				),
-			},
-			{
-				Config: buildFeature(name, newDescription, newEnabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(newEnabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", newDescription),
-				),
-			},
-		},
-	})
-}
-
-func TestAccResourceFeature_updateLabel(t *testing.T) {
-	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	label := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	newDescription := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
-	enabled := randBool()
-	newEnabled := !enabled
-
-	var kv client.FeatureResponse
-
-	resource.ParallelTest(t, resource.TestCase{
-		PreCheck:     func() { preCheck(t) },
-		Providers:    testProviders,
-		CheckDestroy: testCheckFeatureDestroy,
-		Steps: []resource.TestStep{
-			{
-				Config: buildLabeledFeature(name, label, description, enabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
-				),
-			},
-			{
-				Config: buildLabeledFeature(name, label, newDescription, newEnabled),
-				Check: resource.ComposeTestCheckFunc(
-					testCheckFeatureExists("akc_feature.test", &kv),
-					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
-					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
-					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
-					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(newEnabled)),
-					resource.TestCheckResourceAttr("akc_feature.test", "description", newDescription),
-				),
-			},
-		},
-	})
-}
+package akc
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceFeature_create(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	enabled := randBool()
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildLabeledFeature(name, label, description, enabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
+				),
+			},
+			{
+				ResourceName:      "akc_feature.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceFeatureNoLabel_createNoLabel(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	enabled := randBool()
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildFeature(name, description, enabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
+				),
+			},
+			{
+				ResourceName:      "akc_feature.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceFeature_updateNoLabel(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	newDescription := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	enabled := randBool()
+	newEnabled := !enabled
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildFeature(name, description, enabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
+				),
+			},
+			{
+				Config: buildFeature(name, newDescription, newEnabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", client.LabelNone),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(newEnabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", newDescription),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceFeature_updateLabel(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	label := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	newDescription := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	enabled := randBool()
+	newEnabled := !enabled
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildLabeledFeature(name, label, description, enabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(enabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", description),
+				),
+			},
+			{
+				Config: buildLabeledFeature(name, label, newDescription, newEnabled),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_feature.test", "name", name),
+					resource.TestCheckResourceAttr("akc_feature.test", "label", label),
+					resource.TestCheckResourceAttr("akc_feature.test", "enabled", strconv.FormatBool(newEnabled)),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", newDescription),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceFeature_keepsUnmanagedFields(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	label := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	description := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	newDescription := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildLabeledFeature(name, label, description, true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					testAddFeatureField(&kv, "display_name", "Beta"),
+				),
+			},
+			{
+				Config: buildLabeledFeature(name, label, newDescription, true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "description", newDescription),
+					testCheckFeatureField(&kv, "display_name", "Beta"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceFeature_clientFilters(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildFeatureWithFilters(name, 25, client.FeatureRequirementAny),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.#", "4"),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.0.targeting.0.users.0", "alice"),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.0.targeting.0.group.0.rollout_percentage", "50"),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.1.time_window.0.start", "2024-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.2.percentage.0.value", "25"),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.3.name", "MyCustomFilter"),
+					testCheckStoredFilters(&kv, []string{client.TargetingFilterName, client.TimeWindowFilterName, client.PercentageFilterName, "MyCustomFilter"}),
+				),
+			},
+			{
+				Config: buildFeatureWithFilters(name, 75, client.FeatureRequirementAll),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "client_filter.2.percentage.0.value", "75"),
+					resource.TestCheckResourceAttr("akc_feature.test", "requirement_type", client.FeatureRequirementAll),
+				),
+			},
+			{
+				ResourceName:      "akc_feature.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceFeature_variants(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	var kv client.FeatureResponse
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckFeatureDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      buildFeatureWithVariants(name, "Medium"),
+				ExpectError: regexp.MustCompile("references the variant Medium, which is not declared"),
+			},
+			{
+				Config: buildFeatureWithVariants(name, "Big"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFeatureExists("akc_feature.test", &kv),
+					resource.TestCheckResourceAttr("akc_feature.test", "variant.#", "2"),
+					resource.TestCheckResourceAttr("akc_feature.test", "variant.1.status_override", client.VariantStatusDisabled),
+					resource.TestCheckResourceAttr("akc_feature.test", "allocation.0.default_when_enabled", "Big"),
+					resource.TestCheckResourceAttr("akc_feature.test", "allocation.0.percentile.0.to", "20"),
+					resource.TestCheckResourceAttr("akc_feature.test", "telemetry.0.enabled", "true"),
+				),
+			},
+			{
+				ResourceName:      "akc_feature.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -1,210 +1,253 @@
-package akc
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/url"
-	"strings"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
-
-func resourceKeySecret() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceKeySecretCreate,
-		Read:   resourceKeySecretRead,
-		Update: resourceKeySecretUpdate,
-		Delete: resourceKeyValueDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-
-		Schema: map[string]*schema.Schema{
-			"endpoint": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"key": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"secret_id": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"label": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  client.LabelNone,
-				ForceNew: true,
-			},
-			"latest_version": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			"value": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-		},
-		Timeouts: &schema.ResourceTimeout{
-			Read: schema.DefaultTimeout(readTimeout),
-		},
-	}
-}
-
-func resourceKeySecretCreate(d *schema.ResourceData, meta interface{}) error {
-
-	endpoint := d.Get("endpoint").(string)
-	key := d.Get("key").(string)
-	value := d.Get("secret_id").(string)
-	label := d.Get("label").(string)
-	trim := d.Get("latest_version").(bool)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	if trim {
-		value = trimVersion(value)
-	}
-
-	if d.IsNewResource() {
-		err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-			_, err = cl.GetKeyValue(label, key)
-
-			if err != nil {
-				if client.IsNotFound(err) {
-					log.Printf("[INFO] retrying to get key-secret '%s/%s' because: %s", label, key, err)
-
-					return resource.RetryableError(err)
-				}
-
-				return resource.NonRetryableError(err)
-			}
-
-			return nil
-		})
-
-		if err == nil {
-			return fmt.Errorf("the resource needs to be imported: %s", "akc_key_value")
-		}
-	}
-
-	_, err = cl.SetKeyValueSecret(key, value, label)
-	if err != nil {
-		return err
-	}
-
-	id, err := formatID(endpoint, label, key)
-	if err != nil {
-		return err
-	}
-
-	d.SetId(id)
-	d.Set("value", value)
-
-	return resourceKeySecretRead(d, meta)
-}
-
-func resourceKeySecretUpdate(d *schema.ResourceData, meta interface{}) error {
-	endpoint, label, key := parseID(d.Id())
-
-	value := d.Get("secret_id").(string)
-	trim := d.Get("latest_version").(bool)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	if trim {
-		value = trimVersion(value)
-	}
-
-	_, err = cl.SetKeyValueSecret(key, value, label)
-	if err != nil {
-		return err
-	}
-
-	id, err := formatID(endpoint, label, key)
-	if err != nil {
-		return err
-	}
-
-	d.SetId(id)
-	d.Set("value", value)
-
-	return resourceKeySecretRead(d, meta)
-}
-
-func resourceKeySecretRead(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[INFO] Reading resource %s", d.Id())
-
-	endpoint, label, key := parseID(d.Id())
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	var kv client.KeyValueResponse
-	err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-		kv, err = cl.GetKeyValue(label, key)
-
-		if err != nil {
-			if client.IsNotFound(err) {
-				log.Printf("[INFO] retrying to get key-secret '%s/%s' because: %s", label, key, err)
-
-				return resource.RetryableError(err)
-			}
-
-			return resource.NonRetryableError(err)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		log.Printf("[INFO] key-secret not found, removing from state: %s/%s/%s\n", endpoint, label, key)
-		d.SetId("")
-		return nil
-	}
-
-	var wrapper keyVaultReferenceValue
-	err = json.Unmarshal([]byte(kv.Value), &wrapper)
-	if err != nil {
-		return err
-	}
-
-	d.Set("key", key)
-	d.Set("value", wrapper.URI)
-	d.Set("label", label)
-	d.Set("endpoint", endpoint)
-
-	log.Printf("[INFO] the key-secret '%s/%s/%s=%s' was read successfuly\n", endpoint, label, key, wrapper.URI)
-
-	return nil
-}
-
-func trimVersion(s string) string {
-	url, _ := url.Parse(s)
-
-	pathParts := strings.Split(url.Path, "/")
-
-	if len(pathParts) == 4 {
-		url.Path = strings.Join(pathParts[:len(pathParts)-1], "/")
-	}
-
-	return url.String()
-}
-
-type keyVaultReferenceValue struct {
-	URI string
-}
+package akc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceKeySecret() *schema.Resource {
+	return withIDUpgrade(&schema.Resource{
+		CreateContext: resourceKeySecretCreate,
+		ReadContext:   resourceKeySecretRead,
+		UpdateContext: resourceKeySecretUpdate,
+		DeleteContext: resourceKeyValueDelete,
+		Importer:      importState(parseID, parseLegacyID, formatID),
+
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEndpointDiff,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"secret_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+				ForceNew: true,
+			},
+			"latest_version": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"locked": {
+				Type:        schema.TypeBool,
+				Description: "Make the key read-only, Terraform unlocks it temporarily when it needs to change it",
+				Optional:    true,
+				Default:     false,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}, resourceKeySecretV0(), "key", parseLegacyID, formatID)
+}
+
+// resourceKeySecretV0 is the schema of the secret references before version 1, when their ID was host/label/key
+func resourceKeySecretV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"secret_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+				ForceNew: true,
+			},
+			"latest_version": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceKeySecretCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	endpoint := d.Get("endpoint").(string)
+	key := d.Get("key").(string)
+	value := d.Get("secret_id").(string)
+	label := d.Get("label").(string)
+	trim := d.Get("latest_version").(bool)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	if trim {
+		value = trimVersion(value)
+	}
+
+	var createErr error
+	err = writeUnlocked(keyValueLock(ctx, cl, label, key), "", false, d.Get("locked").(bool), func(string) (client.KeyValueResponse, error) {
+		kv, err := cl.SetKeyValueSecretContext(ctx, key, value, label, client.IfNoneMatch("*"))
+		createErr = err
+
+		return kv, err
+	})
+	if client.IsPreconditionFailed(createErr) {
+		return errorDiagnostics(fmt.Sprintf("the resource needs to be imported: %s", "akc_key_secret"), createErr)
+	}
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, key)
+	}
+
+	id, err := formatID(endpoint, label, key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("value", value)
+
+	return resourceKeySecretRead(ctx, d, meta)
+}
+
+func resourceKeySecretUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	endpoint, label, key, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	value := d.Get("secret_id").(string)
+	trim := d.Get("latest_version").(bool)
+	etag := d.Get("etag").(string)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	if trim {
+		value = trimVersion(value)
+	}
+
+	var write func(etag string) (client.KeyValueResponse, error)
+	if d.HasChanges("secret_id", "latest_version") {
+		write = func(etag string) (client.KeyValueResponse, error) {
+			return cl.SetKeyValueSecretContext(ctx, key, value, label, client.IfMatch(etag))
+		}
+	}
+
+	wasLocked, lock := d.GetChange("locked")
+	err = writeUnlocked(keyValueLock(ctx, cl, label, key), etag, wasLocked.(bool), lock.(bool), write)
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, key)
+	}
+
+	id, err := formatID(endpoint, label, key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+	d.Set("value", value)
+
+	return resourceKeySecretRead(ctx, d, meta)
+}
+
+func resourceKeySecretRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Reading resource %s", d.Id())
+
+	endpoint, label, key, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var kv client.KeyValueResponse
+	err = readNewResource(ctx, d, fmt.Sprintf("key-secret '%s/%s'", label, key), func() (err error) {
+		kv, err = cl.GetKeyValueContext(ctx, label, key)
+
+		return err
+	})
+
+	if client.IsNotFound(err) && !d.IsNewResource() {
+		log.Printf("[INFO] key-secret not found, removing from state: %s/%s/%s\n", endpoint, label, key)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting App Configuration key %s/%s", label, key), err)
+	}
+
+	var wrapper keyVaultReferenceValue
+	err = json.Unmarshal([]byte(kv.Value), &wrapper)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error reading the Key Vault reference of App Configuration key %s/%s", label, key), err)
+	}
+
+	d.Set("key", key)
+	d.Set("value", wrapper.URI)
+	d.Set("label", label)
+	d.Set("endpoint", endpoint)
+	d.Set("locked", kv.Locked)
+	d.Set("etag", kv.ETag)
+
+	log.Printf("[INFO] the key-secret '%s/%s/%s=%s' was read successfuly\n", endpoint, label, key, wrapper.URI)
+
+	return nil
+}
+
+func trimVersion(s string) string {
+	url, _ := url.Parse(s)
+
+	pathParts := strings.Split(url.Path, "/")
+
+	if len(pathParts) == 4 {
+		url.Path = strings.Join(pathParts[:len(pathParts)-1], "/")
+	}
+
+	return url.String()
+}
+
+type keyVaultReferenceValue struct {
+	URI string
+}
@@ -1,209 +1,227 @@
-package akc
-
-import (
-	"fmt"
-	"log"
-	"net/url"
-	"strings"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-)
-
-func resourceKeyValue() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceKeyValueCreate,
-		Read:   resourceKeyValueRead,
-		Update: resourceKeyValueUpdate,
-		Delete: resourceKeyValueDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"endpoint": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"key": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"value": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"label": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  client.LabelNone,
-				ForceNew: true,
-			},
-		},
-		Timeouts: &schema.ResourceTimeout{
-			Read: schema.DefaultTimeout(readTimeout),
-		},
-	}
-}
-
-func resourceKeyValueCreate(d *schema.ResourceData, meta interface{}) error {
-	log.Print("[INFO] Creating resource")
-
-	endpoint := d.Get("endpoint").(string)
-	key := d.Get("key").(string)
-	value := d.Get("value").(string)
-	label := d.Get("label").(string)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	if d.IsNewResource() {
-		err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-			_, err = cl.GetKeyValue(label, key)
-
-			if err != nil {
-				if client.IsNotFound(err) {
-					log.Printf("[INFO] retrying to get key-value '%s/%s' because: %s", label, key, err)
-
-					return resource.RetryableError(err)
-				}
-
-				return resource.NonRetryableError(err)
-			}
-
-			return nil
-		})
-
-		if err == nil {
-			return fmt.Errorf("the resource needs to be imported: %s", "akc_key_value")
-		}
-	}
-
-	_, err = cl.SetKeyValue(label, key, value)
-	if err != nil {
-		return err
-	}
-
-	id, err := formatID(endpoint, label, key)
-	if err != nil {
-		return err
-	}
-
-	d.SetId(id)
-
-	return resourceKeyValueRead(d, meta)
-}
-
-func resourceKeyValueRead(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[INFO] Reading resource %s", d.Id())
-
-	endpoint, label, key := parseID(d.Id())
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	var kv client.KeyValueResponse
-	err = resource.Retry(d.Timeout(schema.TimeoutRead), func() *resource.RetryError {
-		kv, err = cl.GetKeyValue(label, key)
-
-		if err != nil {
-			if client.IsNotFound(err) {
-				log.Printf("[INFO] retrying to get key-value '%s/%s' because: %s", label, key, err)
-
-				return resource.RetryableError(err)
-			}
-
-			return resource.NonRetryableError(err)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		log.Printf("[INFO] key-value not found, removing from state: %s/%s/%s", endpoint, label, key)
-		d.SetId("")
-		return nil
-	}
-
-	d.Set("key", key)
-	d.Set("value", kv.Value)
-	d.Set("label", label)
-	d.Set("endpoint", endpoint)
-
-	log.Printf("[INFO] KV has been fetched %s/%s/%s=%s", endpoint, label, key, kv.Value)
-
-	return nil
-}
-
-func resourceKeyValueUpdate(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[INFO] Updating resource %s", d.Id())
-
-	endpoint, label, key := parseID(d.Id())
-
-	value := d.Get("value").(string)
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	_, err = cl.SetKeyValue(label, key, value)
-	if err != nil {
-		return err
-	}
-
-	id, err := formatID(endpoint, label, key)
-	if err != nil {
-		return err
-	}
-
-	d.SetId(id)
-
-	return resourceKeyValueRead(d, meta)
-}
-
-func resourceKeyValueDelete(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[INFO] Deleting resource %s", d.Id())
-
-	endpoint, label, key := parseID(d.Id())
-
-	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
-	if err != nil {
-		return fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
-	}
-
-	_, err = cl.DeleteKeyValue(label, key)
-	if err != nil {
-		return err
-	}
-
-	d.SetId("")
-
-	return nil
-}
-
-func formatID(endpoint string, label string, key string) (string, error) {
-	url, err := url.Parse(endpoint)
-	if err != nil {
-		return "", fmt.Errorf("unable to parse the given endpoint %s", endpoint)
-	}
-
-	host := url.Host
-
-	return fmt.Sprintf("%s/%s/%s", host, label, key), nil
-}
-
-func parseID(id string) (endpoint string, label string, key string) {
-	split := strings.Split(id, "/")
-
-	endpoint = fmt.Sprintf("https://%s", split[0])
-	label = split[1]
-	key = split[2]
-
-	return endpoint, label, key
-}
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceKeyValue() *schema.Resource {
+	return withIDUpgrade(&schema.Resource{
+		CreateContext: resourceKeyValueCreate,
+		ReadContext:   resourceKeyValueRead,
+		UpdateContext: resourceKeyValueUpdate,
+		DeleteContext: resourceKeyValueDelete,
+		Importer:      importState(parseID, parseLegacyID, formatID),
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEndpointDiff,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+				ForceNew: true,
+			},
+			"locked": {
+				Type:        schema.TypeBool,
+				Description: "Make the key read-only, Terraform unlocks it temporarily when it needs to change it",
+				Optional:    true,
+				Default:     false,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}, resourceKeyValueV0(), "key", parseLegacyID, formatID)
+}
+
+// resourceKeyValueV0 is the schema of the key-values before version 1, when their ID was host/label/key
+func resourceKeyValueV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceKeyValueCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Print("[INFO] Creating resource")
+
+	endpoint := d.Get("endpoint").(string)
+	key := d.Get("key").(string)
+	value := d.Get("value").(string)
+	label := d.Get("label").(string)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	// only a conflict of the creation itself means the key already exists, not one of the lock
+	var createErr error
+	err = writeUnlocked(keyValueLock(ctx, cl, label, key), "", false, d.Get("locked").(bool), func(string) (client.KeyValueResponse, error) {
+		kv, err := cl.SetKeyValueContext(ctx, label, key, value, client.IfNoneMatch("*"))
+		createErr = err
+
+		return kv, err
+	})
+	if client.IsPreconditionFailed(createErr) {
+		return errorDiagnostics(fmt.Sprintf("the resource needs to be imported: %s", "akc_key_value"), createErr)
+	}
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, key)
+	}
+
+	id, err := formatID(endpoint, label, key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	return resourceKeyValueRead(ctx, d, meta)
+}
+
+func resourceKeyValueRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Reading resource %s", d.Id())
+
+	endpoint, label, key, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var kv client.KeyValueResponse
+	err = readNewResource(ctx, d, fmt.Sprintf("key-value '%s/%s'", label, key), func() (err error) {
+		kv, err = cl.GetKeyValueContext(ctx, label, key)
+
+		return err
+	})
+
+	if client.IsNotFound(err) && !d.IsNewResource() {
+		log.Printf("[INFO] key-value not found, removing from state: %s/%s/%s", endpoint, label, key)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting App Configuration key %s/%s", label, key), err)
+	}
+
+	d.Set("key", key)
+	d.Set("value", kv.Value)
+	d.Set("label", label)
+	d.Set("endpoint", endpoint)
+	d.Set("locked", kv.Locked)
+	d.Set("etag", kv.ETag)
+
+	log.Printf("[INFO] KV has been fetched %s/%s/%s=%s", endpoint, label, key, kv.Value)
+
+	return nil
+}
+
+func resourceKeyValueUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Updating resource %s", d.Id())
+
+	endpoint, label, key, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	value := d.Get("value").(string)
+	etag := d.Get("etag").(string)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	var write func(etag string) (client.KeyValueResponse, error)
+	if d.HasChange("value") {
+		write = func(etag string) (client.KeyValueResponse, error) {
+			return cl.SetKeyValueContext(ctx, label, key, value, client.IfMatch(etag))
+		}
+	}
+
+	wasLocked, lock := d.GetChange("locked")
+	err = writeUnlocked(keyValueLock(ctx, cl, label, key), etag, wasLocked.(bool), lock.(bool), write)
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, key)
+	}
+
+	id, err := formatID(endpoint, label, key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	return resourceKeyValueRead(ctx, d, meta)
+}
+
+func resourceKeyValueDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Deleting resource %s", d.Id())
+
+	endpoint, label, key, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	err = writeUnlocked(keyValueLock(ctx, cl, label, key), d.Get("etag").(string), d.Get("locked").(bool), false, func(etag string) (client.KeyValueResponse, error) {
+		_, err := cl.DeleteKeyValueContext(ctx, label, key, client.IfMatch(etag))
+		return client.KeyValueResponse{}, err
+	})
+	if err != nil {
+		return keyDiagnostics(err, endpoint, label, key)
+	}
+
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,360 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceKeyValues() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKeyValuesCreate,
+		ReadContext:   resourceKeyValuesRead,
+		UpdateContext: resourceKeyValuesUpdate,
+		DeleteContext: resourceKeyValuesDelete,
+		CustomizeDiff: resourceKeyValuesCustomizeDiff,
+		Importer:      importKeyValues(),
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEndpointDiff,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  client.LabelNone,
+				ForceNew: true,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Description: "Prefix prepended to every key of values (e.g. MyApp:)",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Description: "Key-values to manage, keys are relative to the prefix",
+				Required:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"authoritative": {
+				Type:        schema.TypeBool,
+				Description: "Delete the keys found under the prefix and label which are not part of values, feature flags excepted. Requires a prefix",
+				Optional:    true,
+				Default:     false,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultReadTimeout),
+		},
+	}
+}
+
+func resourceKeyValuesCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Print("[INFO] Creating resource")
+
+	endpoint := d.Get("endpoint").(string)
+	label := d.Get("label").(string)
+	prefix := d.Get("prefix").(string)
+	values := d.Get("values").(map[string]interface{})
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	id, err := formatPrefixID(endpoint, label, prefix)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	errs := setKeyValues(ctx, cl, label, prefix, values)
+
+	if d.Get("authoritative").(bool) {
+		errs = append(errs, deleteUnmanagedKeyValues(ctx, cl, label, prefix, values)...)
+	}
+
+	if len(errs) > 0 {
+		return readAfterKeyValuesFailure(ctx, d, meta, errs)
+	}
+
+	return resourceKeyValuesRead(ctx, d, meta)
+}
+
+func resourceKeyValuesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Reading resource %s", d.Id())
+
+	endpoint, label, prefix, err := parsePrefixID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	managed := d.Get("values").(map[string]interface{})
+	// when authoritative, every key under the prefix belongs to the resource
+	all := d.Get("authoritative").(bool)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	kvs, err := listPrefixKeyValues(ctx, cl, label, prefix)
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error listing App Configuration keys %s/%s", label, prefix), err)
+	}
+
+	values := map[string]string{}
+	for _, kv := range kvs {
+		key := strings.TrimPrefix(kv.Key, prefix)
+		if _, ok := managed[key]; ok || (all && !strings.HasPrefix(kv.Key, client.FeaturePrefix)) {
+			values[key] = kv.Value
+		}
+	}
+
+	if len(values) == 0 && len(managed) > 0 && !all {
+		log.Printf("[INFO] no key-values found, removing from state: %s/%s/%s", endpoint, label, prefix)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("endpoint", endpoint)
+	d.Set("label", label)
+	d.Set("prefix", prefix)
+	d.Set("values", values)
+
+	log.Printf("[INFO] %d key-values have been fetched %s/%s/%s", len(values), endpoint, label, prefix)
+
+	return nil
+}
+
+func resourceKeyValuesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Updating resource %s", d.Id())
+
+	endpoint, label, prefix, err := parsePrefixID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	o, n := d.GetChange("values")
+	oldValues := o.(map[string]interface{})
+	newValues := n.(map[string]interface{})
+
+	changed := map[string]interface{}{}
+	for key, value := range newValues {
+		if oldValue, ok := oldValues[key]; !ok || oldValue != value {
+			changed[key] = value
+		}
+	}
+
+	removed := []string{}
+	for key := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	errs := setKeyValues(ctx, cl, label, prefix, changed)
+	errs = append(errs, deleteKeyValues(ctx, cl, label, prefix, removed)...)
+
+	if d.Get("authoritative").(bool) {
+		errs = append(errs, deleteUnmanagedKeyValues(ctx, cl, label, prefix, newValues)...)
+	}
+
+	if len(errs) > 0 {
+		return readAfterKeyValuesFailure(ctx, d, meta, errs)
+	}
+
+	return resourceKeyValuesRead(ctx, d, meta)
+}
+
+func resourceKeyValuesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Deleting resource %s", d.Id())
+
+	endpoint, label, prefix, err := parsePrefixID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	values := d.Get("values").(map[string]interface{})
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	if errs := deleteKeyValues(ctx, cl, label, prefix, keys); len(errs) > 0 {
+		return keyValuesDiagnostics("deleting", errs)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceKeyValuesCustomizeDiff rejects an authoritative resource without prefix, which would own every key of its label,
+// including the ones of the other resources
+func resourceKeyValuesCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("authoritative") || !diff.NewValueKnown("prefix") {
+		return nil
+	}
+
+	if diff.Get("authoritative").(bool) && diff.Get("prefix").(string) == "" {
+		return fmt.Errorf("an authoritative akc_key_values requires a prefix, since it would delete every other key of its label")
+	}
+
+	return nil
+}
+
+// importKeyValues adopts the keys found under the prefix and label, the import being the only time the resource
+// takes over keys it was not given
+func importKeyValues() *schema.ResourceImporter {
+	importer := importState(parsePrefixID, nil, formatPrefixID)
+
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			result, err := importer.StateContext(ctx, d, meta)
+			if err != nil {
+				return nil, err
+			}
+
+			endpoint, label, prefix, err := parsePrefixID(d.Id())
+			if err != nil {
+				return nil, err
+			}
+
+			cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+			if err != nil {
+				return nil, fmt.Errorf("error building client for endpoint %s: %+v", endpoint, err)
+			}
+
+			kvs, err := listPrefixKeyValues(ctx, cl, label, prefix)
+			if err != nil {
+				return nil, fmt.Errorf("error listing App Configuration keys %s/%s: %+v", label, prefix, err)
+			}
+
+			values := map[string]string{}
+			for _, kv := range kvs {
+				if !strings.HasPrefix(kv.Key, client.FeaturePrefix) {
+					values[strings.TrimPrefix(kv.Key, prefix)] = kv.Value
+				}
+			}
+
+			if err := d.Set("values", values); err != nil {
+				return nil, fmt.Errorf("error setting values: %+v", err)
+			}
+
+			return result, nil
+		},
+	}
+}
+
+func setKeyValues(ctx context.Context, cl *client.Client, label string, prefix string, values map[string]interface{}) []error {
+	errs := []error{}
+	for _, key := range sortedKeys(values) {
+		if _, err := cl.SetKeyValueContext(ctx, label, prefix+key, values[key].(string)); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %+v", prefix, key, err))
+		}
+	}
+
+	return errs
+}
+
+func deleteKeyValues(ctx context.Context, cl *client.Client, label string, prefix string, keys []string) []error {
+	errs := []error{}
+	for _, key := range keys {
+		if _, err := cl.DeleteKeyValueContext(ctx, label, prefix+key); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %+v", prefix, key, err))
+		}
+	}
+
+	return errs
+}
+
+// listPrefixKeyValues lists the key-values of the label whose key starts with the prefix, taken literally
+func listPrefixKeyValues(ctx context.Context, cl *client.Client, label string, prefix string) ([]client.KeyValueResponse, error) {
+	kvs, err := cl.ListKeyValuesContext(ctx, client.EscapeFilter(prefix)+"*", client.EscapeFilter(label))
+	if err != nil {
+		return nil, err
+	}
+
+	result := []client.KeyValueResponse{}
+	for _, kv := range kvs {
+		if strings.HasPrefix(kv.Key, prefix) {
+			result = append(result, kv)
+		}
+	}
+
+	return result, nil
+}
+
+func deleteUnmanagedKeyValues(ctx context.Context, cl *client.Client, label string, prefix string, values map[string]interface{}) []error {
+	kvs, err := listPrefixKeyValues(ctx, cl, label, prefix)
+	if err != nil {
+		return []error{fmt.Errorf("%s*: %+v", prefix, err)}
+	}
+
+	unmanaged := []string{}
+	for _, kv := range kvs {
+		// the feature flags are key-values as well, which belong to akc_feature resources
+		if strings.HasPrefix(kv.Key, client.FeaturePrefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(kv.Key, prefix)
+		if _, ok := values[key]; !ok {
+			log.Printf("[INFO] deleting unmanaged key-value %s/%s", label, kv.Key)
+			unmanaged = append(unmanaged, key)
+		}
+	}
+
+	return deleteKeyValues(ctx, cl, label, prefix, unmanaged)
+}
+
+// readAfterKeyValuesFailure refreshes the state with what was actually applied, so that the failed keys show up in the next plan
+func readAfterKeyValuesFailure(ctx context.Context, d *schema.ResourceData, meta interface{}, errs []error) diag.Diagnostics {
+	return append(keyValuesDiagnostics("applying", errs), resourceKeyValuesRead(ctx, d, meta)...)
+}
+
+func keyValuesDiagnostics(operation string, errs []error) diag.Diagnostics {
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, fmt.Sprintf("  - %s", err))
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("error %s %d key-value(s)", operation, len(errs)),
+			Detail:   strings.Join(messages, "\n"),
+		},
+	}
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
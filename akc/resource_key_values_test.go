@@ -0,0 +1,224 @@
+package akc
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccKeyValues_createAndUpdate(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum) + ":"
+	value := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	newValue := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValuesDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigKeyValues(label, prefix, map[string]string{"one": value, "two": value}, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("akc_key_values.test", "endpoint", endpointUnderTest),
+					resource.TestCheckResourceAttr("akc_key_values.test", "label", label),
+					resource.TestCheckResourceAttr("akc_key_values.test", "prefix", prefix),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.%", "2"),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.one", value),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.two", value),
+				),
+			},
+			{
+				Config: buildTerraformConfigKeyValues(label, prefix, map[string]string{"one": newValue, "three": value}, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.%", "2"),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.one", newValue),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.three", value),
+				),
+			},
+			{
+				ResourceName:            "akc_key_values.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"authoritative"},
+			},
+		},
+	})
+}
+
+func TestAccKeyValues_authoritative(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	prefix := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum) + ":"
+	value := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValuesDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigWithLabel(label, prefix+"unmanaged", value),
+			},
+			{
+				Config: buildTerraformConfigWithLabel(label, prefix+"unmanaged", value) +
+					buildTerraformConfigKeyValues(label, prefix, map[string]string{"one": value}, true),
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.%", "1"),
+					resource.TestCheckResourceAttr("akc_key_values.test", "values.one", value),
+					testCheckKeyValueDestroy,
+				),
+			},
+		},
+	})
+}
+
+func TestAccKeyValues_authoritativeRequiresPrefix(t *testing.T) {
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:  func() { preCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      buildTerraformConfigKeyValues(label, "", map[string]string{"one": "value"}, true),
+				ExpectError: regexp.MustCompile("an authoritative akc_key_values requires a prefix"),
+			},
+		},
+	})
+}
+
+// keyValuesUnderTest creates a key outside of the resource, under a new prefix and label
+func keyValuesUnderTest(t *testing.T) (*client.Client, interface{}, string, string) {
+	if testEmulator == nil {
+		t.Skip("the resource functions are only called directly against the emulator")
+	}
+
+	meta, _ := emulatorConfigure(context.Background(), nil)
+	cl, _ := meta.(func(endpoint string) (*client.Client, error))(endpointUnderTest)
+	label := uuid.New().String()
+	prefix := uuid.New().String() + ":"
+
+	if _, err := cl.SetKeyValue(label, prefix+"unmanaged", "value"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	return cl, meta, label, prefix
+}
+
+func TestKeyValues_emptyValuesDoNotAdoptKeys(t *testing.T) {
+	cl, meta, label, prefix := keyValuesUnderTest(t)
+	r := resourceKeyValues()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"endpoint": endpointUnderTest,
+		"label":    label,
+		"prefix":   prefix,
+		"values":   map[string]interface{}{},
+	})
+	if diags := r.CreateContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+
+	if d.Id() == "" {
+		t.Error("the resource should stay in state")
+	}
+	if values := d.Get("values").(map[string]interface{}); len(values) != 0 {
+		t.Errorf("the keys of the prefix should not be adopted, got %v", values)
+	}
+
+	if diags := r.DeleteContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+	if _, err := cl.GetKeyValue(label, prefix+"unmanaged"); err != nil {
+		t.Errorf("a key the resource does not own should not be deleted: %+v", err)
+	}
+}
+
+func TestKeyValues_importAdoptsKeys(t *testing.T) {
+	_, meta, label, prefix := keyValuesUnderTest(t)
+	r := resourceKeyValues()
+
+	id, _ := formatPrefixID(endpointUnderTest, label, prefix)
+	d := r.Data(nil)
+	d.SetId(id)
+
+	if _, err := r.Importer.StateContext(context.Background(), d, meta); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if diags := r.ReadContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+
+	if value := d.Get("values.unmanaged"); value != "value" {
+		t.Errorf("the keys of the prefix should be imported, got %v", d.Get("values"))
+	}
+}
+
+func TestKeyValues_authoritativeLeavesFeatureFlags(t *testing.T) {
+	cl, meta, label, _ := keyValuesUnderTest(t)
+	r := resourceKeyValues()
+
+	name := uuid.New().String()
+	if _, err := cl.SetFeature(name, label, true, ""); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"endpoint":      endpointUnderTest,
+		"label":         label,
+		"prefix":        ".appconfig.",
+		"authoritative": true,
+		"values":        map[string]interface{}{"mine": "value"},
+	})
+	if diags := r.CreateContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+
+	if values := d.Get("values").(map[string]interface{}); len(values) != 1 {
+		t.Errorf("the feature flags should not be owned by the resource, got %v", values)
+	}
+
+	if errs := deleteUnmanagedKeyValues(context.Background(), cl, label, "", map[string]interface{}{}); len(errs) > 0 {
+		t.Fatalf("%+v", errs)
+	}
+
+	if _, err := cl.GetFeature(label, name); err != nil {
+		t.Errorf("an authoritative resource should leave the feature flags alone: %+v", err)
+	}
+}
+
+func TestKeyValues_prefixIsTakenLiterally(t *testing.T) {
+	cl, meta, label, _ := keyValuesUnderTest(t)
+	r := resourceKeyValues()
+
+	base := uuid.New().String()
+	prefix := base + "*"
+	if _, err := cl.SetKeyValue(label, base+"outside", "value"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"endpoint":      endpointUnderTest,
+		"label":         label,
+		"prefix":        prefix,
+		"authoritative": true,
+		"values":        map[string]interface{}{"one": "value"},
+	})
+	if diags := r.CreateContext(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("%+v", diags)
+	}
+
+	if values := d.Get("values").(map[string]interface{}); len(values) != 1 || values["one"] != "value" {
+		t.Errorf("only the keys under the literal prefix should be owned, got %v", values)
+	}
+	if _, err := cl.GetKeyValue(label, base+"outside"); err != nil {
+		t.Errorf("a key outside the literal prefix should not be deleted: %+v", err)
+	}
+}
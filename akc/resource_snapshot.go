@@ -0,0 +1,314 @@
+package akc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const snapshotCreateTimeout = 30 * time.Minute
+
+func resourceSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSnapshotCreate,
+		ReadContext:   resourceSnapshotRead,
+		UpdateContext: resourceSnapshotUpdate,
+		DeleteContext: resourceSnapshotDelete,
+		CustomizeDiff: resourceSnapshotCustomizeDiff,
+		Importer:      importState(withoutLabel(parseSnapshotID), nil, formatWithoutLabel(formatSnapshotID)),
+		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEndpointDiff,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"filter": {
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+				MaxItems: 3,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Description: "Key filter, accepts wildcards (e.g. MyApp:*)",
+							Required:    true,
+							ForceNew:    true,
+						},
+						"label": {
+							Type:        schema.TypeString,
+							Description: "Label filter, accepts wildcards. Defaults to no label",
+							Optional:    true,
+							ForceNew:    true,
+							Default:     client.LabelNone,
+						},
+					},
+				},
+			},
+			"composition_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      client.SnapshotCompositionKey,
+				ValidateFunc: validation.StringInSlice([]string{client.SnapshotCompositionKey, client.SnapshotCompositionKeyLabel}, false),
+			},
+			"retention_period": {
+				Type:         schema.TypeInt,
+				Description:  "Number of seconds the snapshot is kept once archived",
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(3600, 7776000),
+			},
+			"tags": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"archived": {
+				Type:        schema.TypeBool,
+				Description: "Archive the snapshot, it then expires at the end of its retention period",
+				Optional:    true,
+				Default:     false,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"expires": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"items_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(snapshotCreateTimeout),
+			Read:   schema.DefaultTimeout(defaultReadTimeout),
+			Delete: schema.DefaultTimeout(snapshotCreateTimeout),
+		},
+	}
+}
+
+// resourceSnapshotCustomizeDiff rejects the changes which would replace the snapshot under the same name, since the
+// archived snapshot keeps its name until it expires and the creation of the new one would always fail
+func resourceSnapshotCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" || diff.HasChange("name") || diff.HasChange("endpoint") {
+		return nil
+	}
+
+	for _, key := range []string{"filter", "composition_type", "retention_period", "tags"} {
+		if diff.HasChange(key) {
+			return fmt.Errorf("the %s of the snapshot %s cannot be changed: snapshots are immutable and destroying one only archives it, "+
+				"its name not being reusable until it expires. Create a snapshot with another name instead", key, diff.Get("name"))
+		}
+	}
+
+	return nil
+}
+
+func resourceSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Print("[INFO] Creating resource")
+
+	endpoint := d.Get("endpoint").(string)
+	name := d.Get("name").(string)
+	compositionType := d.Get("composition_type").(string)
+	retentionPeriod := d.Get("retention_period").(int)
+
+	filters := []client.SnapshotFilter{}
+	for _, raw := range d.Get("filter").([]interface{}) {
+		filter := raw.(map[string]interface{})
+		filters = append(filters, client.SnapshotFilter{
+			Key:   filter["key"].(string),
+			Label: filter["label"].(string),
+		})
+	}
+
+	tags := map[string]string{}
+	for key, value := range d.Get("tags").(map[string]interface{}) {
+		tags[key] = value.(string)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	_, err = cl.CreateSnapshotContext(ctx, name, filters, compositionType, retentionPeriod, tags, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error creating snapshot %s", name), err)
+	}
+
+	id, err := formatSnapshotID(endpoint, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	if d.Get("archived").(bool) {
+		if _, err := cl.ArchiveSnapshotContext(ctx, name); err != nil {
+			return errorDiagnostics(fmt.Sprintf("error archiving snapshot %s", name), err)
+		}
+	}
+
+	return resourceSnapshotRead(ctx, d, meta)
+}
+
+func resourceSnapshotRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Reading resource %s", d.Id())
+
+	endpoint, name, err := parseSnapshotID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	snapshot, err := cl.GetSnapshotContext(ctx, name)
+	if client.IsNotFound(err) {
+		log.Printf("[INFO] snapshot not found, removing from state: %s/%s", endpoint, name)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error getting snapshot %s", name), err)
+	}
+
+	filters := []map[string]interface{}{}
+	for _, filter := range snapshot.Filters {
+		filters = append(filters, map[string]interface{}{
+			"key":   filter.Key,
+			"label": labelOrNone(filter.Label),
+		})
+	}
+
+	d.Set("endpoint", endpoint)
+	d.Set("name", name)
+	if err := d.Set("filter", filters); err != nil {
+		return errorDiagnostics("error setting filter", err)
+	}
+	d.Set("composition_type", snapshot.CompositionType)
+	d.Set("retention_period", snapshot.RetentionPeriod)
+	d.Set("tags", snapshot.Tags)
+	d.Set("archived", snapshot.Status == client.SnapshotStatusArchived)
+	d.Set("status", snapshot.Status)
+	d.Set("created", snapshot.Created)
+	d.Set("expires", snapshot.Expires)
+	d.Set("items_count", snapshot.ItemsCount)
+	d.Set("size", snapshot.Size)
+	d.Set("etag", snapshot.ETag)
+
+	log.Printf("[INFO] snapshot has been fetched %s/%s", endpoint, name)
+
+	return nil
+}
+
+func resourceSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Updating resource %s", d.Id())
+
+	endpoint, name, err := parseSnapshotID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	etag := d.Get("etag").(string)
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	if d.Get("archived").(bool) {
+		_, err = cl.ArchiveSnapshotContext(ctx, name, client.IfMatch(etag))
+	} else {
+		_, err = cl.RecoverSnapshotContext(ctx, name, client.IfMatch(etag))
+	}
+
+	if client.IsPreconditionFailed(err) {
+		return changedOutsideDiagnostics(fmt.Sprintf("the snapshot %s/%s", endpoint, name), err)
+	}
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error updating snapshot %s", name), err)
+	}
+
+	return resourceSnapshotRead(ctx, d, meta)
+}
+
+// resourceSnapshotDelete archives the snapshot, since snapshots cannot be deleted: they expire once archived
+func resourceSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] Deleting resource %s", d.Id())
+
+	endpoint, name, err := parseSnapshotID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	cl, err := getClient(endpoint, meta.(func(endpoint string) (*client.Client, error)))
+	if err != nil {
+		return errorDiagnostics(fmt.Sprintf("error building client for endpoint %s", endpoint), err)
+	}
+
+	// a snapshot still being composed can only be archived once it is ready
+	var snapshot client.SnapshotResponse
+	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+		snapshot, err = cl.GetSnapshotContext(ctx, name)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		if snapshot.Status == client.SnapshotStatusProvisioning {
+			log.Printf("[INFO] waiting for the snapshot %s to be provisioned before archiving it", name)
+
+			return resource.RetryableError(fmt.Errorf("the snapshot %s is still provisioning", name))
+		}
+
+		return nil
+	})
+	if err != nil && !client.IsNotFound(err) {
+		return errorDiagnostics(fmt.Sprintf("error getting snapshot %s", name), err)
+	}
+
+	if err == nil && snapshot.Status == client.SnapshotStatusReady {
+		if _, err := cl.ArchiveSnapshotContext(ctx, name); err != nil {
+			return errorDiagnostics(fmt.Sprintf("error archiving snapshot %s", name), err)
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package akc
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccSnapshot_createAndArchive(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	key := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	value := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: buildTerraformConfigSnapshot(name, label, key, value, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("akc_snapshot.test", "name", name),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "status", "ready"),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "composition_type", "key"),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "retention_period", "3600"),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "items_count", "1"),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "filter.0.label", label),
+					resource.TestCheckResourceAttr("data.akc_snapshot.test", "values.%", "1"),
+					resource.TestCheckResourceAttr("data.akc_snapshot.test", "values."+key, value),
+					resource.TestCheckResourceAttr("data.akc_snapshot.test", "items.0.label", label),
+				),
+			},
+			{
+				Config: buildTerraformConfigSnapshot(name, label, key, value, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("akc_snapshot.test", "archived", "true"),
+					resource.TestCheckResourceAttr("akc_snapshot.test", "status", "archived"),
+					resource.TestCheckResourceAttrSet("akc_snapshot.test", "expires"),
+				),
+			},
+			{
+				ResourceName:      "akc_snapshot.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccSnapshot_changesCannotReplace(t *testing.T) {
+	name := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	label := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	key := acctest.RandStringFromCharSet(5, acctest.CharSetAlphaNum)
+	value := acctest.RandStringFromCharSet(20, acctest.CharSetAlphaNum)
+	config := buildTerraformConfigSnapshot(name, label, key, value, false)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { preCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCheckKeyValueDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config:      strings.Replace(config, "retention_period = 3600", "retention_period = 7200", 1),
+				ExpectError: regexp.MustCompile("the retention_period of the snapshot .* cannot be changed"),
+			},
+		},
+	})
+}
@@ -1,397 +1,768 @@
-package akc
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"math/rand"
-	"os"
-	"testing"
-	"time"
-
-	"github.com/arkiaconsulting/terraform-provider-akc/client"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
-)
-
-func preCheck(t *testing.T) {
-	log.Printf("[DEBUG] - testPreCheck\n")
-
-	variables := []string{
-		"ARM_CLIENT_ID",
-		"ARM_CLIENT_SECRET",
-		"ARM_SUBSCRIPTION_ID",
-		"ARM_TENANT_ID",
-	}
-
-	for _, variable := range variables {
-		value := os.Getenv(variable)
-		if value == "" {
-			t.Fatalf("`%s` must be set for acceptance tests!", variable)
-		}
-	}
-}
-
-// BuildTerraformConfig build terraform config
-func buildTerraformConfigWithoutLabel(key string, value string) string {
-	return fmt.Sprintf(`
-resource "akc_key_value" "test" {
-  endpoint     = "%s"
-  key = "%s"
-  value = "%s"
-}
-`, endpointUnderTest, key, value)
-}
-
-func buildTerraformConfigWithLabel(label string, key string, value string) string {
-	return fmt.Sprintf(`
-resource "akc_key_value" "test" {
-  endpoint     = "%s"
-  label = "%s"
-  key = "%s"
-  value = "%s"
-}
-`, endpointUnderTest, label, key, value)
-}
-
-func buildTerraformConfigSecret(label string, key string, secretID string) string {
-	return fmt.Sprintf(`
-resource "akc_key_secret" "test" {
-  endpoint     = "%s"
-  label = "%s"
-  key = "%s"
-  secret_id = "%s"
-}
-`, endpointUnderTest, label, key, secretID)
-}
-
-func buildTerraformConfigSecretNoLabel(key string, secretID string) string {
-	return fmt.Sprintf(`
-resource "akc_key_secret" "test" {
-  endpoint     = "%s"
-  key = "%s"
-  secret_id = "%s"
-}
-`, endpointUnderTest, key, secretID)
-}
-
-func buildTerraformConfigSecretLatestVersion(label string, key string, secretID string, latestVersion bool) string {
-	return fmt.Sprintf(`
-resource "akc_key_secret" "test" {
-  endpoint     = "%s"
-  label = "%s"
-  key = "%s"
-  secret_id = "%s"
-  latest_version = %t
-}
-`, endpointUnderTest, label, key, secretID, latestVersion)
-}
-
-func buildTerraformConfigDataSourceKeyValue(key string) string {
-	return fmt.Sprintf(`
-data "akc_key_value" "test" {
-  endpoint     = "%s"
-  key = "%s"
-}
-`, endpointUnderTest, key)
-}
-
-func buildTerraformConfigDataSourceKeyValueLabel(label string, key string) string {
-	return fmt.Sprintf(`
-data "akc_key_value" "test" {
-  endpoint     = "%s"
-  label = "%s"
-  key = "%s"
-}
-`, endpointUnderTest, label, key)
-}
-
-func buildTerraformConfigDataSourceKeySecret(key string) string {
-	return fmt.Sprintf(`
-data "akc_key_secret" "test" {
-  endpoint     = "%s"
-  key = "%s"
-}
-`, endpointUnderTest, key)
-}
-
-func buildTerraformConfigDataSourceKeySecretLabel(label string, key string) string {
-	return fmt.Sprintf(`
-data "akc_key_secret" "test" {
-  endpoint     = "%s"
-  label = "%s"
-  key = "%s"
-}
-`, endpointUnderTest, label, key)
-}
-
-func buildLabeledFeature(name string, label string, description string, enabled bool) string {
-	return fmt.Sprintf(`
-resource "akc_feature" "test" {
-  endpoint     = "%s"
-  name = "%s"
-  label = "%s"
-  description = "%s"
-  enabled = %t
-}
-`, endpointUnderTest, name, label, description, enabled)
-}
-
-func buildFeature(name string, description string, enabled bool) string {
-	return fmt.Sprintf(`
-resource "akc_feature" "test" {
-  endpoint     = "%s"
-  name = "%s"
-  description = "%s"
-  enabled = %t
-}
-`, endpointUnderTest, name, description, enabled)
-}
-
-func testCheckKeyValueDestroy(state *terraform.State) error {
-	log.Printf("[INFO] Entering Destroy")
-	for _, rs := range state.RootModule().Resources {
-
-		if rs.Type != "akc_key_value" {
-			continue
-		}
-
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Record ID is set")
-		}
-
-		key := rs.Primary.Attributes["key"]
-		label := rs.Primary.Attributes["label"]
-		endpoint := rs.Primary.Attributes["endpoint"]
-		fmt.Printf("checking that the key-value is destroyed %s/%s/%s", endpoint, label, key)
-
-		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
-		if err != nil {
-			return err
-		}
-
-		_, err = cl.GetKeyValue(label, key)
-
-		if !client.IsNotFound(err) {
-			return fmt.Errorf("we expected not to find the key-value, but it's still there")
-		}
-
-		fmt.Println("ok, the key-value was destroyed")
-
-		return nil
-	}
-
-	return nil
-}
-
-func testCheckFeatureDestroy(state *terraform.State) error {
-	log.Printf("[INFO] Entering Destroy")
-	for _, rs := range state.RootModule().Resources {
-
-		if rs.Type != "akc_feature" {
-			continue
-		}
-
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Record ID is set")
-		}
-
-		name := rs.Primary.Attributes["name"]
-		label := rs.Primary.Attributes["label"]
-		endpoint := rs.Primary.Attributes["endpoint"]
-		fmt.Printf("checking that the feature '%s/%s/%s' is destroyed\n", endpoint, label, name)
-
-		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
-		if err != nil {
-			return err
-		}
-
-		_, err = cl.GetFeature(label, name)
-
-		if !client.IsNotFound(err) {
-			return fmt.Errorf("we expected not to find the feature, but it's still there")
-		}
-
-		fmt.Println("ok, the feature was destroyed")
-
-		return nil
-	}
-
-	return nil
-}
-
-func testCheckKeyValueExists(resource string, kv *client.KeyValueResponse) resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		fmt.Printf("checking that the key-value '%s' exists\n", resource)
-
-		rs, ok := state.RootModule().Resources[resource]
-		if !ok {
-			return fmt.Errorf("Not found: %s", resource)
-		}
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Record ID is set")
-		}
-
-		endpoint := rs.Primary.Attributes["endpoint"]
-		key := rs.Primary.Attributes["key"]
-		value := rs.Primary.Attributes["value"]
-		label := rs.Primary.Attributes["label"]
-
-		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
-		if err != nil {
-			return err
-		}
-
-		result, err := cl.GetKeyValue(label, key)
-		if client.IsNotFound(err) {
-			// let some time for eventual consistency
-			time.Sleep(5 * time.Second)
-			result, err = cl.GetKeyValue(label, key)
-
-			if client.IsNotFound(err) {
-				return fmt.Errorf("Cannot find resource %s", resource)
-			}
-		}
-
-		if err != nil {
-			return fmt.Errorf("Error fetching KV with resource %s. %s", resource, err)
-		}
-
-		if result.Value != value {
-			return fmt.Errorf("The given key %s exists but its value does not match", key)
-		}
-
-		fmt.Printf("the key-value '%s' was found\n", resource)
-
-		*kv = result
-
-		return nil
-	}
-}
-
-func testCheckKeyValueSecretExists(resource string, kv *client.KeyValueResponse) resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		fmt.Printf("checking that the key-secret '%s' exists\n", resource)
-
-		rs, ok := state.RootModule().Resources[resource]
-		if !ok {
-			return fmt.Errorf("Not found: %s", resource)
-		}
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Record ID is set")
-		}
-
-		endpoint := rs.Primary.Attributes["endpoint"]
-		key := rs.Primary.Attributes["key"]
-		label := rs.Primary.Attributes["label"]
-
-		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
-		if err != nil {
-			return err
-		}
-
-		result, err := cl.GetKeyValue(label, key)
-		if client.IsNotFound(err) {
-			// let some time for eventual consistency
-			time.Sleep(5 * time.Second)
-			result, err = cl.GetKeyValue(label, key)
-
-			if client.IsNotFound(err) {
-				return fmt.Errorf("Cannot find resource %s", resource)
-			}
-		}
-
-		if err != nil {
-			return fmt.Errorf("Error fetching KV with resource %s. %s", resource, err)
-		}
-
-		fmt.Printf("the key-secret '%s' was found\n", resource)
-
-		*kv = result
-
-		return nil
-	}
-}
-
-func testCheckFeatureExists(resource string, kv *client.FeatureResponse) resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		fmt.Printf("checking that the feature '%s' exists\n", resource)
-
-		rs, ok := state.RootModule().Resources[resource]
-		if !ok {
-			return fmt.Errorf("Not found: %s", resource)
-		}
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Record ID is set")
-		}
-
-		endpoint := rs.Primary.Attributes["endpoint"]
-		name := rs.Primary.Attributes["name"]
-		label := rs.Primary.Attributes["label"]
-
-		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
-		if err != nil {
-			return err
-		}
-
-		result, err := cl.GetFeature(label, name)
-		if client.IsNotFound(err) {
-
-			// let some time for eventual consistency
-			time.Sleep(5 * time.Second)
-			result, err = cl.GetFeature(label, name)
-			if client.IsNotFound(err) {
-				return fmt.Errorf("error checking that the resource '%s' exists (%s)", resource, err)
-			}
-		}
-
-		if err != nil {
-			return fmt.Errorf("Error fetching feature with resource %s. %s", resource, err)
-		}
-
-		fmt.Printf("the feature '%s' was found\n", resource)
-
-		*kv = result
-
-		return nil
-	}
-}
-
-func testCheckStoredSecretID(kv *client.KeyValueResponse, expectedSecretID string) resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		fmt.Printf("checking that the right secret ID '%s' was stored\n", expectedSecretID)
-
-		var actualStoredValue keyVaultReferenceValue
-		err := json.Unmarshal([]byte(kv.Value), &actualStoredValue)
-		if err != nil {
-			return fmt.Errorf("Error while deserializing key-secret value")
-		}
-
-		if actualStoredValue.URI != expectedSecretID {
-			return fmt.Errorf("Stored secret ID '%s' does not match expected one '%s'", actualStoredValue.URI, expectedSecretID)
-		}
-
-		fmt.Println("ok, the right secret ID was stored")
-
-		return nil
-	}
-}
-
-func testCheckStoredValue(kv *client.KeyValueResponse, expectedValue string) resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		fmt.Printf("checking that the value '%s' was stored\n", expectedValue)
-
-		if kv.Value != expectedValue {
-			return fmt.Errorf("Stored value '%s' does not match expected one '%s'", kv.Value, expectedValue)
-		}
-
-		fmt.Println("ok, the right value was stored")
-
-		return nil
-	}
-}
-
-func randBool() bool {
-	rand.Seed(time.Now().UnixNano())
-	return rand.Intn(2) == 1
-}
+package akc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arkiaconsulting/terraform-provider-akc/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func preCheck(t *testing.T) {
+	log.Printf("[DEBUG] - testPreCheck\n")
+
+	if testEmulator != nil {
+		return
+	}
+
+	variables := []string{
+		"ARM_CLIENT_ID",
+		"ARM_CLIENT_SECRET",
+		"ARM_SUBSCRIPTION_ID",
+		"ARM_TENANT_ID",
+	}
+
+	for _, variable := range variables {
+		value := os.Getenv(variable)
+		if value == "" {
+			t.Fatalf("`%s` must be set for acceptance tests!", variable)
+		}
+	}
+}
+
+// BuildTerraformConfig build terraform config
+func buildTerraformConfigWithoutLabel(key string, value string) string {
+	return fmt.Sprintf(`
+resource "akc_key_value" "test" {
+  endpoint     = "%s"
+  key = "%s"
+  value = "%s"
+}
+`, endpointUnderTest, key, value)
+}
+
+func buildTerraformConfigWithLabel(label string, key string, value string) string {
+	return fmt.Sprintf(`
+resource "akc_key_value" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+  value = "%s"
+}
+`, endpointUnderTest, label, key, value)
+}
+
+func buildTerraformConfigLocked(label string, key string, value string, locked bool) string {
+	return fmt.Sprintf(`
+resource "akc_key_value" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+  value = "%s"
+  locked = %t
+}
+`, endpointUnderTest, label, key, value, locked)
+}
+
+func buildTerraformConfigSecret(label string, key string, secretID string) string {
+	return fmt.Sprintf(`
+resource "akc_key_secret" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+  secret_id = "%s"
+}
+`, endpointUnderTest, label, key, secretID)
+}
+
+func buildTerraformConfigSecretNoLabel(key string, secretID string) string {
+	return fmt.Sprintf(`
+resource "akc_key_secret" "test" {
+  endpoint     = "%s"
+  key = "%s"
+  secret_id = "%s"
+}
+`, endpointUnderTest, key, secretID)
+}
+
+func buildTerraformConfigSecretLatestVersion(label string, key string, secretID string, latestVersion bool) string {
+	return fmt.Sprintf(`
+resource "akc_key_secret" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+  secret_id = "%s"
+  latest_version = %t
+}
+`, endpointUnderTest, label, key, secretID, latestVersion)
+}
+
+func buildTerraformConfigDataSourceKeyValue(key string) string {
+	return fmt.Sprintf(`
+data "akc_key_value" "test" {
+  endpoint     = "%s"
+  key = "%s"
+}
+`, endpointUnderTest, key)
+}
+
+func buildTerraformConfigDataSourceKeyValueLabel(label string, key string) string {
+	return fmt.Sprintf(`
+data "akc_key_value" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+}
+`, endpointUnderTest, label, key)
+}
+
+func buildTerraformConfigDataSourceKeySecret(key string) string {
+	return fmt.Sprintf(`
+data "akc_key_secret" "test" {
+  endpoint     = "%s"
+  key = "%s"
+}
+`, endpointUnderTest, key)
+}
+
+func buildTerraformConfigDataSourceKeySecretLabel(label string, key string) string {
+	return fmt.Sprintf(`
+data "akc_key_secret" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+}
+`, endpointUnderTest, label, key)
+}
+
+func buildTerraformConfigDataSourceKeyValues(label string, prefix string, values map[string]string) string {
+	config := ""
+	for key, value := range values {
+		config += fmt.Sprintf(`
+resource "akc_key_value" "%s" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s%s"
+  value = "%s"
+}
+`, key, endpointUnderTest, label, prefix, key, value)
+	}
+
+	return config + fmt.Sprintf(`
+data "akc_key_values" "test" {
+  endpoint     = "%s"
+  prefix = "%s"
+  labels = ["%s"]
+
+  depends_on = [%s]
+}
+`, endpointUnderTest, prefix, label, resourceAddresses("akc_key_value", values))
+}
+
+func buildTerraformConfigDataSourceFeatures(label string, prefix string, descriptions map[string]string) string {
+	config := ""
+	for name, description := range descriptions {
+		config += fmt.Sprintf(`
+resource "akc_feature" "%s" {
+  endpoint    = "%s"
+  label       = "%s"
+  name        = "%s%s"
+  description = "%s"
+  enabled     = true
+
+  client_filter {
+    percentage {
+      value = 50
+    }
+  }
+}
+`, name, endpointUnderTest, label, prefix, name, description)
+	}
+
+	return config + fmt.Sprintf(`
+data "akc_features" "test" {
+  endpoint = "%s"
+  label    = "%s"
+  prefix   = "%s"
+
+  depends_on = [%s]
+}
+`, endpointUnderTest, label, prefix, resourceAddresses("akc_feature", descriptions))
+}
+
+func buildTerraformConfigDataSourceLabels(key string, prefix string, labels map[string]string) string {
+	config := ""
+	for name, label := range labels {
+		config += fmt.Sprintf(`
+resource "akc_key_value" "%s" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s"
+  value = "value"
+}
+`, name, endpointUnderTest, label, key)
+	}
+
+	return config + fmt.Sprintf(`
+data "akc_labels" "test" {
+  endpoint     = "%s"
+  prefix = "%s"
+
+  depends_on = [%s]
+}
+`, endpointUnderTest, prefix, resourceAddresses("akc_key_value", labels))
+}
+
+func buildTerraformConfigDataSourceKeys(label string, prefix string, values map[string]string) string {
+	config := ""
+	for key, value := range values {
+		config += fmt.Sprintf(`
+resource "akc_key_value" "%s" {
+  endpoint     = "%s"
+  label = "%s"
+  key = "%s%s"
+  value = "%s"
+}
+`, key, endpointUnderTest, label, prefix, key, value)
+	}
+
+	return config + fmt.Sprintf(`
+resource "akc_key_value" "no_label" {
+  endpoint     = "%s"
+  key = "%sone"
+  value = "value"
+}
+
+data "akc_keys" "test" {
+  endpoint     = "%s"
+  prefix = "%s"
+
+  depends_on = [akc_key_value.no_label, %s]
+}
+`, endpointUnderTest, prefix, endpointUnderTest, prefix, resourceAddresses("akc_key_value", values))
+}
+
+func buildTerraformConfigDataSourceKeyRevisions(label string, key string, value string) string {
+	return fmt.Sprintf(`
+%s
+
+data "akc_key_revisions" "test" {
+  endpoint     = akc_key_value.test.endpoint
+  label = akc_key_value.test.label
+  key = akc_key_value.test.key
+}
+`, buildTerraformConfigWithLabel(label, key, value))
+}
+
+func buildTerraformConfigSnapshot(name string, label string, key string, value string, archived bool) string {
+	return fmt.Sprintf(`
+%s
+
+resource "akc_snapshot" "test" {
+  endpoint     = akc_key_value.test.endpoint
+  name = "%s"
+  retention_period = 3600
+  archived = %t
+
+  filter {
+    key = akc_key_value.test.key
+    label = akc_key_value.test.label
+  }
+}
+
+data "akc_snapshot" "test" {
+  endpoint     = akc_snapshot.test.endpoint
+  name = akc_snapshot.test.name
+}
+`, buildTerraformConfigWithLabel(label, key, value), name, archived)
+}
+
+func buildTerraformConfigKeyValues(label string, prefix string, values map[string]string, authoritative bool) string {
+	entries := ""
+	for key, value := range values {
+		entries += fmt.Sprintf(`
+    "%s" = "%s"`, key, value)
+	}
+
+	return fmt.Sprintf(`
+resource "akc_key_values" "test" {
+  endpoint     = "%s"
+  label = "%s"
+  prefix = "%s"
+  authoritative = %t
+  values = {%s
+  }
+}
+`, endpointUnderTest, label, prefix, authoritative, entries)
+}
+
+func resourceAddresses(resourceType string, names map[string]string) string {
+	addresses := []string{}
+	for name := range names {
+		addresses = append(addresses, fmt.Sprintf("%s.%s", resourceType, name))
+	}
+
+	return strings.Join(addresses, ", ")
+}
+
+func buildLabeledFeature(name string, label string, description string, enabled bool) string {
+	return fmt.Sprintf(`
+resource "akc_feature" "test" {
+  endpoint     = "%s"
+  name = "%s"
+  label = "%s"
+  description = "%s"
+  enabled = %t
+}
+`, endpointUnderTest, name, label, description, enabled)
+}
+
+func buildFeature(name string, description string, enabled bool) string {
+	return fmt.Sprintf(`
+resource "akc_feature" "test" {
+  endpoint     = "%s"
+  name = "%s"
+  description = "%s"
+  enabled = %t
+}
+`, endpointUnderTest, name, description, enabled)
+}
+
+func buildFeatureWithVariants(name string, defaultVariant string) string {
+	return fmt.Sprintf(`
+resource "akc_feature" "test" {
+  endpoint = "%s"
+  name     = "%s"
+  enabled  = true
+
+  variant {
+    name                = "Big"
+    configuration_value = jsonencode({ Size = 500 })
+  }
+
+  variant {
+    name                = "Small"
+    configuration_value = jsonencode("small")
+    status_override     = "Disabled"
+  }
+
+  allocation {
+    default_when_enabled  = "%s"
+    default_when_disabled = "Small"
+    user {
+      variant = "Big"
+      users   = ["alice"]
+    }
+    percentile {
+      variant = "Big"
+      from    = 0
+      to      = 20
+    }
+    seed = "release"
+  }
+
+  telemetry {
+    enabled = true
+  }
+}
+`, endpointUnderTest, name, defaultVariant)
+}
+
+func buildFeatureWithFilters(name string, percentage int, requirementType string) string {
+	return fmt.Sprintf(`
+resource "akc_feature" "test" {
+  endpoint         = "%s"
+  name             = "%s"
+  enabled          = true
+  requirement_type = "%s"
+
+  client_filter {
+    targeting {
+      users = ["alice"]
+      group {
+        name               = "beta"
+        rollout_percentage = 50
+      }
+      default_rollout_percentage = 10
+    }
+  }
+
+  client_filter {
+    time_window {
+      start = "2024-01-01T00:00:00Z"
+    }
+  }
+
+  client_filter {
+    percentage {
+      value = %d
+    }
+  }
+
+  client_filter {
+    name       = "MyCustomFilter"
+    parameters = jsonencode({ Region = "eu" })
+  }
+}
+`, endpointUnderTest, name, requirementType, percentage)
+}
+
+func testCheckKeyValueDestroy(state *terraform.State) error {
+	log.Printf("[INFO] Entering Destroy")
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "akc_key_value" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Record ID is set")
+		}
+
+		key := rs.Primary.Attributes["key"]
+		label := rs.Primary.Attributes["label"]
+		endpoint := rs.Primary.Attributes["endpoint"]
+		fmt.Printf("checking that the key-value is destroyed %s/%s/%s", endpoint, label, key)
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		_, err = cl.GetKeyValue(label, key)
+
+		if !client.IsNotFound(err) {
+			return fmt.Errorf("we expected not to find the key-value, but it's still there")
+		}
+
+		fmt.Println("ok, the key-value was destroyed")
+
+		return nil
+	}
+
+	return nil
+}
+
+func testCheckFeatureDestroy(state *terraform.State) error {
+	log.Printf("[INFO] Entering Destroy")
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "akc_feature" {
+			continue
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Record ID is set")
+		}
+
+		name := rs.Primary.Attributes["name"]
+		label := rs.Primary.Attributes["label"]
+		endpoint := rs.Primary.Attributes["endpoint"]
+		fmt.Printf("checking that the feature '%s/%s/%s' is destroyed\n", endpoint, label, name)
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		_, err = cl.GetFeature(label, name)
+
+		if !client.IsNotFound(err) {
+			return fmt.Errorf("we expected not to find the feature, but it's still there")
+		}
+
+		fmt.Println("ok, the feature was destroyed")
+
+		return nil
+	}
+
+	return nil
+}
+
+func testCheckKeyValuesDestroy(state *terraform.State) error {
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "akc_key_values" {
+			continue
+		}
+
+		label := rs.Primary.Attributes["label"]
+		prefix := rs.Primary.Attributes["prefix"]
+		endpoint := rs.Primary.Attributes["endpoint"]
+		fmt.Printf("checking that the key-values are destroyed %s/%s/%s\n", endpoint, label, prefix)
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		kvs, err := cl.ListKeyValues(prefixFilter(prefix), label)
+		if err != nil {
+			return err
+		}
+
+		if len(kvs) != 0 {
+			return fmt.Errorf("we expected not to find any key-value, but %d are still there", len(kvs))
+		}
+
+		fmt.Println("ok, the key-values were destroyed")
+	}
+
+	return nil
+}
+
+func testCheckKeyValueExists(resource string, kv *client.KeyValueResponse) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the key-value '%s' exists\n", resource)
+
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Record ID is set")
+		}
+
+		endpoint := rs.Primary.Attributes["endpoint"]
+		key := rs.Primary.Attributes["key"]
+		value := rs.Primary.Attributes["value"]
+		label := rs.Primary.Attributes["label"]
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		result, err := cl.GetKeyValue(label, key)
+		if client.IsNotFound(err) {
+			// let some time for eventual consistency
+			time.Sleep(5 * time.Second)
+			result, err = cl.GetKeyValue(label, key)
+
+			if client.IsNotFound(err) {
+				return fmt.Errorf("Cannot find resource %s", resource)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error fetching KV with resource %s. %s", resource, err)
+		}
+
+		if result.Value != value {
+			return fmt.Errorf("The given key %s exists but its value does not match", key)
+		}
+
+		fmt.Printf("the key-value '%s' was found\n", resource)
+
+		*kv = result
+
+		return nil
+	}
+}
+
+func testCheckKeyValueSecretExists(resource string, kv *client.KeyValueResponse) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the key-secret '%s' exists\n", resource)
+
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Record ID is set")
+		}
+
+		endpoint := rs.Primary.Attributes["endpoint"]
+		key := rs.Primary.Attributes["key"]
+		label := rs.Primary.Attributes["label"]
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		result, err := cl.GetKeyValue(label, key)
+		if client.IsNotFound(err) {
+			// let some time for eventual consistency
+			time.Sleep(5 * time.Second)
+			result, err = cl.GetKeyValue(label, key)
+
+			if client.IsNotFound(err) {
+				return fmt.Errorf("Cannot find resource %s", resource)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error fetching KV with resource %s. %s", resource, err)
+		}
+
+		fmt.Printf("the key-secret '%s' was found\n", resource)
+
+		*kv = result
+
+		return nil
+	}
+}
+
+func testCheckFeatureExists(resource string, kv *client.FeatureResponse) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the feature '%s' exists\n", resource)
+
+		rs, ok := state.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resource)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Record ID is set")
+		}
+
+		endpoint := rs.Primary.Attributes["endpoint"]
+		name := rs.Primary.Attributes["name"]
+		label := rs.Primary.Attributes["label"]
+
+		cl, err := getClient(endpoint, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		result, err := cl.GetFeature(label, name)
+		if client.IsNotFound(err) {
+
+			// let some time for eventual consistency
+			time.Sleep(5 * time.Second)
+			result, err = cl.GetFeature(label, name)
+			if client.IsNotFound(err) {
+				return fmt.Errorf("error checking that the resource '%s' exists (%s)", resource, err)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error fetching feature with resource %s. %s", resource, err)
+		}
+
+		fmt.Printf("the feature '%s' was found\n", resource)
+
+		*kv = result
+
+		return nil
+	}
+}
+
+// testAddFeatureField writes a field the provider does not manage into a feature flag, as the portal would
+func testAddFeatureField(kv *client.FeatureResponse, field string, value string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("adding the field '%s' to the feature '%s'\n", field, kv.Key)
+
+		cl, err := getClient(endpointUnderTest, testProviders["akc"].Meta().(func(endpoint string) (*client.Client, error)))
+		if err != nil {
+			return err
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(kv.Raw, &fields); err != nil {
+			return fmt.Errorf("Error while deserializing feature value: %s", err)
+		}
+
+		fields[field] = value
+		feature := kv.Feature
+		if feature.Raw, err = json.Marshal(fields); err != nil {
+			return err
+		}
+
+		_, err = cl.SetFeatureFlag(kv.Key, labelOrNone(kv.Label), feature, client.IfMatch(kv.ETag))
+
+		return err
+	}
+}
+
+func testCheckFeatureField(kv *client.FeatureResponse, field string, expectedValue string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the field '%s' of the feature '%s' is '%s'\n", field, kv.Key, expectedValue)
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(kv.Raw, &fields); err != nil {
+			return fmt.Errorf("Error while deserializing feature value: %s", err)
+		}
+
+		if fields[field] != expectedValue {
+			return fmt.Errorf("Stored field '%s' is '%v' instead of '%s'", field, fields[field], expectedValue)
+		}
+
+		return nil
+	}
+}
+
+func testCheckStoredSecretID(kv *client.KeyValueResponse, expectedSecretID string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the right secret ID '%s' was stored\n", expectedSecretID)
+
+		var actualStoredValue keyVaultReferenceValue
+		err := json.Unmarshal([]byte(kv.Value), &actualStoredValue)
+		if err != nil {
+			return fmt.Errorf("Error while deserializing key-secret value")
+		}
+
+		if actualStoredValue.URI != expectedSecretID {
+			return fmt.Errorf("Stored secret ID '%s' does not match expected one '%s'", actualStoredValue.URI, expectedSecretID)
+		}
+
+		fmt.Println("ok, the right secret ID was stored")
+
+		return nil
+	}
+}
+
+func testCheckStoredValue(kv *client.KeyValueResponse, expectedValue string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the value '%s' was stored\n", expectedValue)
+
+		if kv.Value != expectedValue {
+			return fmt.Errorf("Stored value '%s' does not match expected one '%s'", kv.Value, expectedValue)
+		}
+
+		fmt.Println("ok, the right value was stored")
+
+		return nil
+	}
+}
+
+func testCheckStoredFilters(kv *client.FeatureResponse, expectedNames []string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		names := []string{}
+		for _, filter := range kv.Conditions.ClientFilters {
+			names = append(names, filter.Name)
+		}
+
+		if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+			return fmt.Errorf("the stored filters %v are not the expected ones %v", names, expectedNames)
+		}
+
+		return nil
+	}
+}
+
+func testCheckStoredLock(kv *client.KeyValueResponse, expectedLocked bool) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		fmt.Printf("checking that the stored lock is %t\n", expectedLocked)
+
+		if kv.Locked != expectedLocked {
+			return fmt.Errorf("Stored lock '%t' does not match expected one '%t'", kv.Locked, expectedLocked)
+		}
+
+		fmt.Println("ok, the right lock was stored")
+
+		return nil
+	}
+}
+
+func randBool() bool {
+	rand.Seed(time.Now().UnixNano())
+	return rand.Intn(2) == 1
+}
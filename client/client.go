@@ -1,295 +1,720 @@
-package client
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"strings"
-	"time"
-
-	"github.com/Azure/go-autorest/autorest"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
-	"github.com/arkiaconsulting/terraform-provider-akc/utils"
-)
-
-var (
-	LabelNone     = "%00"
-	FeaturePrefix = ".appconfig.featureflag/"
-)
-
-func toPrefixedFeature(key string) string {
-	return fmt.Sprintf("%s%s", FeaturePrefix, key)
-}
-
-var (
-	defaultContentType     = "application/vnd.microsoft.appconfig.kv+json"
-	keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"
-	featureContentType     = "application/vnd.microsoft.appconfig.ff+json;charset=utf-8"
-)
-
-type Client struct {
-	*autorest.Client
-	Endpoint string
-}
-
-type setKeyValuePayload struct {
-	Value       string
-	ContentType string `json:"content_type"`
-}
-
-type featurePayload struct {
-	Id         string                   `json:"id"`
-	Descripton string                   `json:"description"`
-	Enabled    bool                     `json:"enabled"`
-	Conditions featureConditionsPayload `json:"conditions"`
-}
-
-type featureConditionsPayload struct {
-	ClientFilters []string `json:"client_filters"`
-}
-
-func NewClientCreds(endpoint string, clientID string, clientSecret string, tenantID string) (*Client, error) {
-
-	ccc := auth.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
-	ccc.Resource = endpoint
-
-	authorizer, err := ccc.Authorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	return NewClient(endpoint, authorizer)
-}
-
-func NewClientCli(endpoint string) (*Client, error) {
-	authorizer, err := auth.NewAuthorizerFromCLIWithResource(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return NewClient(endpoint, authorizer)
-}
-
-func NewClientMsi(endpoint string) (*Client, error) {
-	msiConfig := auth.NewMSIConfig()
-	msiConfig.Resource = endpoint
-
-	authorizer, err := msiConfig.Authorizer()
-	if err != nil {
-		return nil, err
-	}
-	return NewClient(endpoint, authorizer)
-}
-
-func NewClient(endpoint string, authorizer autorest.Authorizer) (*Client, error) {
-	client := autorest.NewClientWithUserAgent(userAgent())
-	client.Authorizer = authorizer
-
-	return &Client{
-		Client:   &client,
-		Endpoint: endpoint,
-	}, nil
-}
-
-func (client *Client) GetKeyValue(label string, key string) (KeyValueResponse, error) {
-	result := KeyValueResponse{}
-	resp, err := client.send(
-		label,
-		url.QueryEscape(key),
-		autorest.AsGet(),
-	)
-
-	if err != nil {
-		return result, err
-	}
-
-	if utils.ResponseWasNotFound(resp) {
-		return result, KVNotFoundError.with(key)
-	}
-
-	if err = getJSON(resp, &result); err != nil {
-		return result, UnexpectedError.wrap(err)
-	}
-
-	return result, nil
-}
-
-func (client *Client) SetKeyValue(label string, key string, value string) (KeyValueResponse, error) {
-	return client.setKeyValue(label, key, value, defaultContentType)
-}
-
-func (client *Client) SetKeyValueSecret(key string, secretID string, label string) (KeyValueResponse, error) {
-	value := fmt.Sprintf("{\"uri\":\"%s\"}", secretID)
-	return client.setKeyValue(label, key, value, keyVaultRefContentType)
-}
-
-func (client *Client) SetFeature(key string, label string, enabled bool, description string) (KeyValueResponse, error) {
-	actualKey := fmt.Sprintf(".appconfig.featureflag%%2F%s", key)
-
-	featurePayload := featurePayload{
-		Id:         key,
-		Descripton: description,
-		Enabled:    enabled,
-		Conditions: featureConditionsPayload{
-			ClientFilters: []string{},
-		},
-	}
-
-	b, err := json.Marshal(featurePayload)
-	if err != nil {
-		return KeyValueResponse{}, UnexpectedError.wrap(err)
-	}
-
-	return client.setKeyValue(label, actualKey, string(b), featureContentType)
-}
-
-func (client *Client) GetFeature(label string, key string) (FeatureResponse, error) {
-	kvResponse, err := client.GetKeyValue(label, toPrefixedFeature(key))
-	if err != nil {
-		return FeatureResponse{}, err
-	}
-
-	details := featurePayload{}
-	err = json.Unmarshal([]byte(kvResponse.Value), &details)
-	if err != nil {
-		return FeatureResponse{}, UnexpectedError.wrap(err)
-	}
-
-	resp := FeatureResponse{
-		Key:          strings.TrimPrefix(kvResponse.Key, FeaturePrefix),
-		Label:        kvResponse.Label,
-		ContentType:  kvResponse.ContentType,
-		LastModified: kvResponse.LastModified,
-		Tags:         kvResponse.Tags,
-		Description:  details.Descripton,
-		Enabled:      details.Enabled,
-	}
-
-	return resp, nil
-}
-
-func (client *Client) DeleteFeature(label string, key string) (bool, error) {
-	return client.DeleteKeyValue(label, toPrefixedFeature(key))
-}
-
-func (client *Client) setKeyValue(label string, key string, value string, contentType string) (KeyValueResponse, error) {
-	result := KeyValueResponse{}
-	payload := setKeyValuePayload{
-		Value:       value,
-		ContentType: contentType,
-	}
-
-	resp, err := client.send(
-		label,
-		key,
-		autorest.AsContentType(defaultContentType),
-		autorest.AsPut(),
-		autorest.WithJSON(payload),
-	)
-	if err != nil {
-		return result, err
-	}
-
-	if utils.ResponseWasStatusCode(resp, http.StatusForbidden) {
-		return result, UnexpectedError.wrap(fmt.Errorf("Forbidden"))
-	}
-
-	err = getJSON(resp, &result)
-	if err != nil {
-		return result, UnexpectedError.wrap(err)
-	}
-
-	return result, nil
-}
-
-func (client *Client) DeleteKeyValue(label string, key string) (bool, error) {
-	resp, err := client.send(
-		label,
-		url.QueryEscape(key),
-		autorest.AsDelete(),
-	)
-	if err != nil {
-		return false, err
-	}
-
-	if resp.StatusCode == http.StatusNoContent {
-		return false, nil
-	}
-
-	return true, nil
-}
-
-func userAgent() string {
-	return "Azure-SDK-For-Go/" + "1.0" + " akc-key-value/2020-03-01"
-}
-
-func getJSON(response *http.Response, target interface{}) error {
-	defer response.Body.Close()
-
-	dec := json.NewDecoder(response.Body)
-
-	err := dec.Decode(target)
-
-	return err
-}
-
-func (client *Client) send(label string, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Response, error) {
-	req, err := client.getPreparer(
-		label,
-		key,
-		additionalDecorator...,
-	).Prepare(&http.Request{})
-
-	if err != nil {
-		return nil, UnexpectedError.wrap(err)
-	}
-
-	var resp *http.Response
-	retryDecorator := autorest.DoRetryForStatusCodes(5, 2*time.Second, 500, 503, 502)
-	resp, err = client.Send(req, retryDecorator)
-
-	if err != nil {
-		return nil, UnexpectedError.wrap(err)
-	}
-
-	if utils.ResponseWasNotFound(resp) {
-		return nil, KVNotFoundError.with("Not found")
-	}
-
-	if utils.ResponseWasThrottled(resp) {
-		return nil, UnexpectedError.with("Requests are throttled")
-	}
-
-	if utils.ResponseWasForbidden(resp) {
-		return nil, UnexpectedError.with("Forbidden")
-	}
-
-	if utils.ResponseWasUnauthorized(resp) {
-		return nil, UnexpectedError.with("Unauthorized")
-	}
-
-	return resp, err
-}
-
-func (client *Client) getPreparer(label string, key string, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
-	const apiVersion = "1.0"
-	queryParameters := map[string]interface{}{
-		"label":       label,
-		"api-version": apiVersion,
-	}
-
-	pathParameters := map[string]interface{}{
-		"key": key,
-	}
-
-	decorators := []autorest.PrepareDecorator{
-		autorest.WithBaseURL(client.Endpoint),
-		autorest.WithPathParameters("/kv/{key}", pathParameters),
-		autorest.WithQueryParameters(queryParameters),
-		client.Client.WithAuthorization(),
-	}
-
-	decorators = append(decorators, additionalDecorators...)
-
-	return autorest.CreatePreparer(decorators...)
-}
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+var (
+	LabelNone     = "%00"
+	FeaturePrefix = ".appconfig.featureflag/"
+)
+
+func toPrefixedFeature(key string) string {
+	return fmt.Sprintf("%s%s", FeaturePrefix, key)
+}
+
+var (
+	defaultContentType     = "application/vnd.microsoft.appconfig.kv+json"
+	keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"
+	featureContentType     = "application/vnd.microsoft.appconfig.ff+json;charset=utf-8"
+)
+
+type Client struct {
+	*autorest.Client
+	Endpoint string
+	// MaxRetries is the number of times a throttled or transiently failed request is retried, 0 disabling retries
+	MaxRetries int
+	// RetryMaxWait is the longest wait between two attempts
+	RetryMaxWait time.Duration
+}
+
+type setKeyValuePayload struct {
+	Value       string `json:"value"`
+	ContentType string `json:"content_type"`
+}
+
+type keyValueListPayload struct {
+	Items    []KeyValueResponse `json:"items"`
+	NextLink string             `json:"@nextLink"`
+}
+
+type featurePayload struct {
+	Id         string             `json:"id"`
+	Descripton string             `json:"description"`
+	Enabled    bool               `json:"enabled"`
+	Conditions FeatureConditions  `json:"conditions"`
+	Variants   []FeatureVariant   `json:"variants,omitempty"`
+	Allocation *FeatureAllocation `json:"allocation,omitempty"`
+	Telemetry  *FeatureTelemetry  `json:"telemetry,omitempty"`
+}
+
+func NewClientCreds(endpoint string, clientID string, clientSecret string, tenantID string) (*Client, error) {
+
+	ccc := auth.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
+	ccc.Resource = endpoint
+
+	authorizer, err := ccc.Authorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(endpoint, authorizer)
+}
+
+func NewClientCli(endpoint string) (*Client, error) {
+	authorizer, err := auth.NewAuthorizerFromCLIWithResource(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(endpoint, authorizer)
+}
+
+func NewClientMsi(endpoint string) (*Client, error) {
+	msiConfig := auth.NewMSIConfig()
+	msiConfig.Resource = endpoint
+
+	authorizer, err := msiConfig.Authorizer()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(endpoint, authorizer)
+}
+
+// NewClientConnectionString builds a client authenticated with an access key connection string (Endpoint=...;Id=...;Secret=...)
+func NewClientConnectionString(connectionString string) (*Client, error) {
+	cs, err := ParseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientAccessKey(cs.Endpoint, cs.ID, cs.Secret)
+}
+
+// NewClientAccessKey builds a client signing its requests with the given access key
+func NewClientAccessKey(endpoint string, id string, secret string) (*Client, error) {
+	authorizer, err := newHmacAuthorizer(id, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(endpoint, authorizer)
+}
+
+func NewClient(endpoint string, authorizer autorest.Authorizer) (*Client, error) {
+	client := autorest.NewClientWithUserAgent(userAgent())
+	client.Authorizer = authorizer
+
+	return &Client{
+		Client:       &client,
+		Endpoint:     endpoint,
+		MaxRetries:   DefaultMaxRetries,
+		RetryMaxWait: DefaultRetryMaxWait,
+	}, nil
+}
+
+// GetKeyValue gets a key-value, use the AsOf option to get it as it was at a given time
+func (client *Client) GetKeyValue(label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.GetKeyValueContext(context.Background(), label, key, options...)
+}
+
+// GetKeyValueContext is GetKeyValue, the requests being canceled with the context
+func (client *Client) GetKeyValueContext(ctx context.Context, label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	result := KeyValueResponse{}
+	resp, err := client.send(
+		ctx,
+		label,
+		key,
+		autorest.AsGet(),
+		withOptions(options),
+	)
+
+	if IsNotFound(err) {
+		return result, KVNotFoundError.from(key, err)
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	if err = getJSON(resp, &result); err != nil {
+		return result, UnexpectedError.wrap(err)
+	}
+
+	result.ETag = etagOf(resp, result.ETag)
+
+	return result, nil
+}
+
+// ListKeyValues lists the key-values matching the given key and label filters, across all pages.
+// Both filters accept wildcards (e.g. "MyApp:*") and the label filter accepts a comma-separated list.
+// An empty filter is not sent, so that any key (or label) matches.
+func (client *Client) ListKeyValues(keyFilter string, labelFilter string) ([]KeyValueResponse, error) {
+	return client.ListKeyValuesContext(context.Background(), keyFilter, labelFilter)
+}
+
+// ListKeyValuesContext is ListKeyValues, the requests being canceled with the context
+func (client *Client) ListKeyValuesContext(ctx context.Context, keyFilter string, labelFilter string) ([]KeyValueResponse, error) {
+	result := []KeyValueResponse{}
+
+	queryParameters := map[string]interface{}{}
+	if keyFilter != "" {
+		queryParameters["key"] = keyFilter
+	}
+	if labelFilter != "" {
+		queryParameters["label"] = labelParameter(labelFilter)
+	}
+
+	err := client.list(ctx, "/kv", queryParameters, nil, func(resp *http.Response) (string, error) {
+		page := keyValueListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		result = append(result, page.Items...)
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListRevisions lists the revisions of the key-values matching the given key and label filters, most recent first.
+// Revisions older than from or more recent than to are left out, unless these are zero.
+func (client *Client) ListRevisions(keyFilter string, labelFilter string, from time.Time, to time.Time) ([]KeyValueResponse, error) {
+	return client.ListRevisionsContext(context.Background(), keyFilter, labelFilter, from, to)
+}
+
+// ListRevisionsContext is ListRevisions, the requests being canceled with the context
+func (client *Client) ListRevisionsContext(ctx context.Context, keyFilter string, labelFilter string, from time.Time, to time.Time) ([]KeyValueResponse, error) {
+	result := []KeyValueResponse{}
+
+	queryParameters := map[string]interface{}{}
+	if keyFilter != "" {
+		queryParameters["key"] = keyFilter
+	}
+	if labelFilter != "" {
+		queryParameters["label"] = labelParameter(labelFilter)
+	}
+
+	options := []RequestOption{}
+	if !to.IsZero() {
+		options = append(options, AsOf(to))
+	}
+
+	err := client.list(ctx, "/revisions", queryParameters, options, func(resp *http.Response) (string, error) {
+		page := keyValueListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		for _, revision := range page.Items {
+			if !from.IsZero() {
+				lastModified, err := time.Parse(time.RFC3339, revision.LastModified)
+				if err != nil {
+					return "", err
+				}
+				if lastModified.Before(from) {
+					continue
+				}
+			}
+
+			result = append(result, revision)
+		}
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetKeyValue creates or updates a key-value, use the IfMatch/IfNoneMatch options to make it conditional
+func (client *Client) SetKeyValue(label string, key string, value string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.SetKeyValueContext(context.Background(), label, key, value, options...)
+}
+
+// SetKeyValueContext is SetKeyValue, the requests being canceled with the context
+func (client *Client) SetKeyValueContext(ctx context.Context, label string, key string, value string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.setKeyValue(ctx, label, key, value, defaultContentType, options...)
+}
+
+// SetKeyValueSecret creates or updates a Key Vault reference, use the IfMatch/IfNoneMatch options to make it conditional
+func (client *Client) SetKeyValueSecret(key string, secretID string, label string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.SetKeyValueSecretContext(context.Background(), key, secretID, label, options...)
+}
+
+// SetKeyValueSecretContext is SetKeyValueSecret, the requests being canceled with the context
+func (client *Client) SetKeyValueSecretContext(ctx context.Context, key string, secretID string, label string, options ...RequestOption) (KeyValueResponse, error) {
+	value := fmt.Sprintf("{\"uri\":\"%s\"}", secretID)
+	return client.setKeyValue(ctx, label, key, value, keyVaultRefContentType, options...)
+}
+
+// SetFeature creates or updates a feature flag without filters, variants nor allocation, use the IfMatch/IfNoneMatch
+// options to make it conditional. SetFeatureFlag writes the whole feature flag.
+func (client *Client) SetFeature(key string, label string, enabled bool, description string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.SetFeatureContext(context.Background(), key, label, enabled, description, options...)
+}
+
+// SetFeatureContext is SetFeature, the requests being canceled with the context
+func (client *Client) SetFeatureContext(ctx context.Context, key string, label string, enabled bool, description string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.SetFeatureFlagContext(ctx, key, label, Feature{Enabled: enabled, Description: description}, options...)
+}
+
+// SetFeatureFlag creates or updates a feature flag, use the IfMatch/IfNoneMatch options to make it conditional
+func (client *Client) SetFeatureFlag(key string, label string, feature Feature, options ...RequestOption) (KeyValueResponse, error) {
+	return client.SetFeatureFlagContext(context.Background(), key, label, feature, options...)
+}
+
+// SetFeatureFlagContext is SetFeatureFlag, the requests being canceled with the context
+func (client *Client) SetFeatureFlagContext(ctx context.Context, key string, label string, feature Feature, options ...RequestOption) (KeyValueResponse, error) {
+	actualKey := toPrefixedFeature(key)
+
+	conditions := feature.Conditions
+	if conditions.ClientFilters == nil {
+		conditions.ClientFilters = []FeatureFilter{}
+	}
+
+	featurePayload := featurePayload{
+		Id:         key,
+		Descripton: feature.Description,
+		Enabled:    feature.Enabled,
+		Conditions: conditions,
+		Variants:   feature.Variants,
+		Allocation: feature.Allocation,
+		Telemetry:  feature.Telemetry,
+	}
+
+	b, err := mergeFeature(feature.Raw, featurePayload)
+	if err != nil {
+		return KeyValueResponse{}, UnexpectedError.wrap(err)
+	}
+
+	return client.setKeyValue(ctx, label, actualKey, string(b), featureContentType, options...)
+}
+
+// GetFeature gets a feature flag, use the AsOf option to get it as it was at a given time
+func (client *Client) GetFeature(label string, key string, options ...RequestOption) (FeatureResponse, error) {
+	return client.GetFeatureContext(context.Background(), label, key, options...)
+}
+
+// GetFeatureContext is GetFeature, the requests being canceled with the context
+func (client *Client) GetFeatureContext(ctx context.Context, label string, key string, options ...RequestOption) (FeatureResponse, error) {
+	kvResponse, err := client.GetKeyValueContext(ctx, label, toPrefixedFeature(key), options...)
+	if err != nil {
+		return FeatureResponse{}, err
+	}
+
+	return toFeatureResponse(kvResponse)
+}
+
+// ListFeatures lists the feature flags whose name matches the given filter, across all pages.
+// The name filter accepts a trailing wildcard (e.g. "Beta*"), an empty one matching any flag.
+// The flags whose value cannot be decoded are skipped, so that one of them does not hide all the others.
+func (client *Client) ListFeatures(nameFilter string, labelFilter string) ([]FeatureResponse, error) {
+	return client.ListFeaturesContext(context.Background(), nameFilter, labelFilter)
+}
+
+// ListFeaturesContext is ListFeatures, the requests being canceled with the context
+func (client *Client) ListFeaturesContext(ctx context.Context, nameFilter string, labelFilter string) ([]FeatureResponse, error) {
+	if nameFilter == "" {
+		nameFilter = "*"
+	}
+
+	kvs, err := client.ListKeyValuesContext(ctx, toPrefixedFeature(nameFilter), labelFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []FeatureResponse{}
+	for _, kv := range kvs {
+		feature, err := toFeatureResponse(kv)
+		if err != nil {
+			log.Printf("[WARN] skipping the feature flag %s/%s, its value cannot be decoded: %s", kv.Label, kv.Key, err)
+			continue
+		}
+
+		result = append(result, feature)
+	}
+
+	return result, nil
+}
+
+// toFeatureResponse decodes the value of the key-value of a feature flag
+func toFeatureResponse(kvResponse KeyValueResponse) (FeatureResponse, error) {
+	details := featurePayload{}
+	err := json.Unmarshal([]byte(kvResponse.Value), &details)
+	if err != nil {
+		return FeatureResponse{}, UnexpectedError.wrap(err)
+	}
+
+	resp := FeatureResponse{
+		Key:          strings.TrimPrefix(kvResponse.Key, FeaturePrefix),
+		Label:        kvResponse.Label,
+		ContentType:  kvResponse.ContentType,
+		LastModified: kvResponse.LastModified,
+		Tags:         kvResponse.Tags,
+		ETag:         kvResponse.ETag,
+		Locked:       kvResponse.Locked,
+		Feature: Feature{
+			Description: details.Descripton,
+			Enabled:     details.Enabled,
+			Conditions:  details.Conditions,
+			Variants:    details.Variants,
+			Allocation:  details.Allocation,
+			Telemetry:   details.Telemetry,
+			Raw:         json.RawMessage(kvResponse.Value),
+		},
+	}
+
+	return resp, nil
+}
+
+// DeleteFeature deletes a feature flag, use the IfMatch option to make it conditional
+func (client *Client) DeleteFeature(label string, key string, options ...RequestOption) (bool, error) {
+	return client.DeleteFeatureContext(context.Background(), label, key, options...)
+}
+
+// DeleteFeatureContext is DeleteFeature, the requests being canceled with the context
+func (client *Client) DeleteFeatureContext(ctx context.Context, label string, key string, options ...RequestOption) (bool, error) {
+	return client.DeleteKeyValueContext(ctx, label, toPrefixedFeature(key), options...)
+}
+
+func (client *Client) setKeyValue(ctx context.Context, label string, key string, value string, contentType string, options ...RequestOption) (KeyValueResponse, error) {
+	result := KeyValueResponse{}
+	payload := setKeyValuePayload{
+		Value:       value,
+		ContentType: contentType,
+	}
+
+	resp, err := client.send(
+		ctx,
+		label,
+		key,
+		autorest.AsContentType(defaultContentType),
+		autorest.AsPut(),
+		autorest.WithJSON(payload),
+		withOptions(options),
+	)
+	if err != nil {
+		return result, err
+	}
+
+	err = getJSON(resp, &result)
+	if err != nil {
+		return result, UnexpectedError.wrap(err)
+	}
+
+	result.ETag = etagOf(resp, result.ETag)
+
+	return result, nil
+}
+
+// LockKeyValue makes a key-value read-only, use the IfMatch option to make it conditional
+func (client *Client) LockKeyValue(label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.LockKeyValueContext(context.Background(), label, key, options...)
+}
+
+// LockKeyValueContext is LockKeyValue, the requests being canceled with the context
+func (client *Client) LockKeyValueContext(ctx context.Context, label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.setLock(ctx, label, key, autorest.AsPut(), options...)
+}
+
+// UnlockKeyValue makes a key-value writable again, use the IfMatch option to make it conditional
+func (client *Client) UnlockKeyValue(label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.UnlockKeyValueContext(context.Background(), label, key, options...)
+}
+
+// UnlockKeyValueContext is UnlockKeyValue, the requests being canceled with the context
+func (client *Client) UnlockKeyValueContext(ctx context.Context, label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.setLock(ctx, label, key, autorest.AsDelete(), options...)
+}
+
+// LockFeature makes a feature flag read-only, use the IfMatch option to make it conditional
+func (client *Client) LockFeature(label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.LockFeatureContext(context.Background(), label, key, options...)
+}
+
+// LockFeatureContext is LockFeature, the requests being canceled with the context
+func (client *Client) LockFeatureContext(ctx context.Context, label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.LockKeyValueContext(ctx, label, toPrefixedFeature(key), options...)
+}
+
+// UnlockFeature makes a feature flag writable again, use the IfMatch option to make it conditional
+func (client *Client) UnlockFeature(label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.UnlockFeatureContext(context.Background(), label, key, options...)
+}
+
+// UnlockFeatureContext is UnlockFeature, the requests being canceled with the context
+func (client *Client) UnlockFeatureContext(ctx context.Context, label string, key string, options ...RequestOption) (KeyValueResponse, error) {
+	return client.UnlockKeyValueContext(ctx, label, toPrefixedFeature(key), options...)
+}
+
+func (client *Client) setLock(ctx context.Context, label string, key string, method autorest.PrepareDecorator, options ...RequestOption) (KeyValueResponse, error) {
+	result := KeyValueResponse{}
+	resp, err := client.sendLock(
+		ctx,
+		label,
+		key,
+		method,
+		withOptions(options),
+	)
+	if err != nil {
+		return result, err
+	}
+
+	if err = getJSON(resp, &result); err != nil {
+		return result, UnexpectedError.wrap(err)
+	}
+
+	result.ETag = etagOf(resp, result.ETag)
+
+	return result, nil
+}
+
+// DeleteKeyValue deletes a key-value, use the IfMatch option to make it conditional
+func (client *Client) DeleteKeyValue(label string, key string, options ...RequestOption) (bool, error) {
+	return client.DeleteKeyValueContext(context.Background(), label, key, options...)
+}
+
+// DeleteKeyValueContext is DeleteKeyValue, the requests being canceled with the context
+func (client *Client) DeleteKeyValueContext(ctx context.Context, label string, key string, options ...RequestOption) (bool, error) {
+	resp, err := client.send(
+		ctx,
+		label,
+		key,
+		autorest.AsDelete(),
+		withOptions(options),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func userAgent() string {
+	return "Azure-SDK-For-Go/" + "1.0" + " akc-key-value/2020-03-01"
+}
+
+// etagOf returns the ETag found in the body, or the one of the ETag header
+func etagOf(response *http.Response, bodyETag string) string {
+	if bodyETag != "" {
+		return bodyETag
+	}
+
+	return unquoteETag(response.Header.Get("ETag"))
+}
+
+func getJSON(response *http.Response, target interface{}) error {
+	defer response.Body.Close()
+
+	dec := json.NewDecoder(response.Body)
+
+	err := dec.Decode(target)
+
+	return err
+}
+
+func (client *Client) send(ctx context.Context, label string, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Response, error) {
+	return client.sendPrepared(ctx, client.getPreparer(
+		"/kv/{key}",
+		label,
+		key,
+		additionalDecorator...,
+	))
+}
+
+func (client *Client) sendLock(ctx context.Context, label string, key string, additionalDecorator ...autorest.PrepareDecorator) (*http.Response, error) {
+	return client.sendPrepared(ctx, client.getPreparer(
+		"/locks/{key}",
+		label,
+		key,
+		additionalDecorator...,
+	))
+}
+
+// list walks through a paginated collection: readPage consumes one page and returns the
+// @nextLink found in its body, if any. The Link header takes precedence over it.
+func (client *Client) list(ctx context.Context, path string, queryParameters map[string]interface{}, options []RequestOption, readPage func(resp *http.Response) (string, error)) error {
+	preparer := client.getListPreparer(path, queryParameters, options)
+
+	for {
+		resp, err := client.sendPrepared(ctx, preparer)
+		if err != nil {
+			return err
+		}
+
+		bodyLink, err := readPage(resp)
+		if err != nil {
+			return UnexpectedError.wrap(err)
+		}
+
+		link := nextLink(resp.Header.Get("Link"))
+		if link == "" {
+			link = bodyLink
+		}
+
+		if link == "" {
+			return nil
+		}
+
+		nextURL, err := client.resolve(link)
+		if err != nil {
+			return UnexpectedError.wrap(err)
+		}
+
+		preparer = client.getNextPagePreparer(nextURL, options)
+	}
+}
+
+// sendPrepared sends the request. It is authorized by the autorest client right before each attempt, so that
+// the signature of a retried request is not computed before the wait.
+// A conditional write failing after a replay is resolved by reading the item again.
+func (client *Client) sendPrepared(ctx context.Context, preparer autorest.Preparer) (*http.Response, error) {
+	tracker := &replay{}
+	req, err := preparer.Prepare((&http.Request{}).WithContext(context.WithValue(ctx, replayKey{}, tracker)))
+
+	if err != nil {
+		return nil, UnexpectedError.wrap(err)
+	}
+
+	var body []byte
+	if isConditionalWrite(req) {
+		if body, err = keepBody(req); err != nil {
+			return nil, UnexpectedError.wrap(err)
+		}
+	}
+
+	resp, err := client.Send(req, client.withRetries())
+
+	if err != nil {
+		return nil, UnexpectedError.wrap(err)
+	}
+
+	if tracker.happened && isConditionalWrite(req) && (resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict) {
+		resp = client.resolveReplayedWrite(req, body, resp)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, errorOf(resp)
+	}
+
+	return resp, err
+}
+
+func (client *Client) getPreparer(path string, label string, key string, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
+	const apiVersion = "1.0"
+	queryParameters := map[string]interface{}{
+		"label":       labelParameter(label),
+		"api-version": apiVersion,
+	}
+
+	pathParameters := map[string]interface{}{
+		"key": escapePathSegment(key),
+	}
+
+	decorators := []autorest.PrepareDecorator{
+		autorest.WithBaseURL(client.Endpoint),
+		autorest.WithPathParameters(path, pathParameters),
+		withQueryParameters(queryParameters),
+	}
+
+	decorators = append(decorators, additionalDecorators...)
+
+	return autorest.CreatePreparer(decorators...)
+}
+
+func (client *Client) getListPreparer(path string, queryParameters map[string]interface{}, options []RequestOption) autorest.Preparer {
+	const apiVersion = "1.0"
+	if _, ok := queryParameters["api-version"]; !ok {
+		queryParameters["api-version"] = apiVersion
+	}
+
+	return autorest.CreatePreparer(
+		autorest.WithBaseURL(client.Endpoint),
+		autorest.WithPath(path),
+		withQueryParameters(queryParameters),
+		autorest.AsGet(),
+		withOptions(options),
+	)
+}
+
+func (client *Client) getNextPagePreparer(nextURL string, options []RequestOption) autorest.Preparer {
+	return autorest.CreatePreparer(
+		autorest.WithBaseURL(nextURL),
+		autorest.AsGet(),
+		withOptions(options),
+	)
+}
+
+// resolve makes an absolute URL from a link returned by the service, which is usually relative to the endpoint
+func (client *Client) resolve(link string) (string, error) {
+	base, err := url.Parse(client.Endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+// nextLink extracts the target of the rel="next" entry of a Link header, e.g. </kv?after=abc>; rel="next".
+// The target is read up to its closing bracket before the parameters are split, since it may hold raw commas.
+func nextLink(header string) string {
+	rest := header
+	for {
+		start := strings.Index(rest, "<")
+		if start < 0 {
+			return ""
+		}
+		end := strings.Index(rest[start:], ">")
+		if end < 0 {
+			return ""
+		}
+
+		target := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		// the parameters of the entry run up to the next comma which is not quoted
+		i, quoted := 0, false
+		for ; i < len(rest) && (quoted || rest[i] != ','); i++ {
+			if rest[i] == '"' {
+				quoted = !quoted
+			}
+		}
+
+		for _, param := range strings.Split(rest[:i], ";") {
+			param = strings.ReplaceAll(strings.TrimSpace(param), " ", "")
+			if param == `rel="next"` || param == "rel=next" {
+				return target
+			}
+		}
+
+		rest = rest[i:]
+	}
+}
@@ -1,58 +1,216 @@
-package client
-
-import (
-	"fmt"
-)
-
-var (
-	// KVNotFoundError The given App Configuration key-value was not found
-	KVNotFoundError = AppConfigClientError{Message: "KV not found"}
-	// UnexpectedError An unexpected error has occurred
-	UnexpectedError = AppConfigClientError{Message: "Unexpected error"}
-)
-
-// AppConfigClientError Main type for AppConfigClient errors
-type AppConfigClientError struct {
-	Message string
-	Info    string
-	Err     error
-}
-
-func (err AppConfigClientError) Error() string {
-	if err.Err != nil {
-		return fmt.Sprintf("%s (%s): %v", err.Message, err.Info, err.Err.Error())
-	}
-	return fmt.Sprintf("%s (%s)", err.Message, err.Info)
-}
-func (err AppConfigClientError) wrap(inner error) error {
-	return AppConfigClientError{Message: err.Message, Err: inner}
-}
-func (err AppConfigClientError) with(info string) error {
-	return AppConfigClientError{Message: err.Message, Info: info}
-}
-func (err AppConfigClientError) Unwrap() error {
-	return err.Err
-}
-
-// Is Wether the given error is an AppConfigClientError
-func (err AppConfigClientError) Is(target error) bool {
-	t, ok := target.(AppConfigClientError)
-	if !ok {
-		return false
-	}
-
-	return t.Error() == err.Message
-}
-
-func IsNotFound(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	e, ok := err.(AppConfigClientError)
-	if !ok {
-		return false
-	}
-
-	return e.Message == KVNotFoundError.Message
-}
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// KVNotFoundError The given App Configuration key-value, feature flag or snapshot was not found (404)
+	KVNotFoundError = AppConfigClientError{Message: "KV not found"}
+	// KVLockedError The key-value is read-only and cannot be modified, or the request conflicts with the item (409)
+	KVLockedError = AppConfigClientError{Message: "KV locked"}
+	// PreconditionFailedError The key-value does not match the ETag condition of the request (412)
+	PreconditionFailedError = AppConfigClientError{Message: "Precondition failed"}
+	// ForbiddenError The identity is not allowed to perform the request (403)
+	ForbiddenError = AppConfigClientError{Message: "Forbidden"}
+	// UnauthorizedError The request could not be authenticated (401)
+	UnauthorizedError = AppConfigClientError{Message: "Unauthorized"}
+	// ThrottledError The requests are throttled, the ResponseError tells when to retry (429)
+	ThrottledError = AppConfigClientError{Message: "Requests are throttled"}
+	// SnapshotExistsError A snapshot with the given name already exists
+	SnapshotExistsError = AppConfigClientError{Message: "Snapshot already exists"}
+	// SnapshotFailedError The creation of the snapshot has failed
+	SnapshotFailedError = AppConfigClientError{Message: "Snapshot creation failed"}
+	// UnexpectedError An unexpected error has occurred
+	UnexpectedError = AppConfigClientError{Message: "Unexpected error"}
+)
+
+// AppConfigClientError Main type for AppConfigClient errors. The errors of the package match the sentinel
+// errors above with errors.Is, and the failed responses of the service can be inspected with
+// errors.As(err, &responseError), responseError being a *ResponseError.
+type AppConfigClientError struct {
+	Message string
+	Info    string
+	Err     error
+}
+
+func (err AppConfigClientError) Error() string {
+	message := err.Message
+	if err.Info != "" {
+		message = fmt.Sprintf("%s (%s)", message, err.Info)
+	}
+
+	if err.Err != nil {
+		return fmt.Sprintf("%s: %v", message, err.Err.Error())
+	}
+
+	return message
+}
+func (err AppConfigClientError) wrap(inner error) error {
+	return AppConfigClientError{Message: err.Message, Err: inner}
+}
+func (err AppConfigClientError) with(info string) error {
+	return AppConfigClientError{Message: err.Message, Info: info}
+}
+
+// from describes the given error as this one, keeping the failed response it may wrap
+func (err AppConfigClientError) from(info string, cause error) error {
+	result := AppConfigClientError{Message: err.Message, Info: info}
+
+	var responseError *ResponseError
+	if errors.As(cause, &responseError) {
+		result.Err = responseError
+	}
+
+	return result
+}
+func (err AppConfigClientError) Unwrap() error {
+	return err.Err
+}
+
+// Is tells whether the given error is the sentinel error this one is an instance of
+func (err AppConfigClientError) Is(target error) bool {
+	t, ok := target.(AppConfigClientError)
+	if !ok {
+		return false
+	}
+
+	return t.Message == err.Message
+}
+
+// Problem is the detail of a failure, as App Configuration returns it in an application/problem+json body
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+// ResponseError describes a failed response of App Configuration
+type ResponseError struct {
+	StatusCode int
+	// RequestID is the x-ms-request-id of the response, which the support of the service asks for
+	RequestID string
+	// RetryAfter is the delay the service asks to wait before retrying, zero when it does not say
+	RetryAfter time.Duration
+	// Problem is the parsed body of the response, nil when it is not a problem
+	Problem *Problem
+}
+
+func (err *ResponseError) Error() string {
+	message := fmt.Sprintf("%d %s", err.StatusCode, http.StatusText(err.StatusCode))
+	if err.RequestID != "" {
+		message += fmt.Sprintf(", request ID %s", err.RequestID)
+	}
+	if err.RetryAfter > 0 {
+		message += fmt.Sprintf(", retry after %s", err.RetryAfter)
+	}
+
+	if err.Problem != nil {
+		detail := err.Problem.Detail
+		if detail == "" {
+			detail = err.Problem.Title
+		}
+		if detail != "" {
+			message += ": " + detail
+		}
+		if err.Problem.Name != "" {
+			message += fmt.Sprintf(" (%s)", err.Problem.Name)
+		}
+	}
+
+	return message
+}
+
+// newResponseError reads the failed response, closing its body
+func newResponseError(resp *http.Response) *ResponseError {
+	defer resp.Body.Close()
+
+	result := &ResponseError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("x-ms-request-id"),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		body, err := ioutil.ReadAll(resp.Body)
+		problem := Problem{}
+		if err == nil && json.Unmarshal(body, &problem) == nil {
+			result.Problem = &problem
+		}
+	}
+
+	return result
+}
+
+// errorOf maps a failed response to the sentinel error of its status code
+func errorOf(resp *http.Response) error {
+	sentinel := UnexpectedError
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		sentinel = KVNotFoundError
+	case http.StatusConflict:
+		sentinel = KVLockedError
+	case http.StatusPreconditionFailed:
+		sentinel = PreconditionFailedError
+	case http.StatusForbidden:
+		sentinel = ForbiddenError
+	case http.StatusUnauthorized:
+		sentinel = UnauthorizedError
+	case http.StatusTooManyRequests:
+		sentinel = ThrottledError
+	}
+
+	return sentinel.wrap(newResponseError(resp))
+}
+
+// parseRetryAfter reads the delay the service asks to wait before the next request, from retry-after-ms
+// or from Retry-After, in seconds or as a date. It is zero when none is given.
+func parseRetryAfter(header http.Header) time.Duration {
+	if milliseconds, err := strconv.ParseInt(header.Get("retry-after-ms"), 10, 64); err == nil && milliseconds > 0 {
+		return time.Duration(milliseconds) * time.Millisecond
+	}
+
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(time.Now()) {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, KVNotFoundError)
+}
+
+func IsPreconditionFailed(err error) bool {
+	return errors.Is(err, PreconditionFailedError)
+}
+
+func IsLocked(err error) bool {
+	return errors.Is(err, KVLockedError)
+}
+
+func IsForbidden(err error) bool {
+	return errors.Is(err, ForbiddenError)
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, UnauthorizedError)
+}
+
+func IsThrottled(err error) bool {
+	return errors.Is(err, ThrottledError)
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestErrorTestSuite(t *testing.T) {
+	suiteTester := new(errorTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type errorTestSuite struct {
+	suite.Suite
+	uri    string
+	client *Client
+}
+
+func (s *errorTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *errorTestSuite) TestSentinelErrorsShouldMatchWithErrorsIs() {
+	err := fmt.Errorf("reading: %w", KVNotFoundError.with("myKey"))
+
+	assert.True(s.T(), errors.Is(err, KVNotFoundError))
+	assert.True(s.T(), IsNotFound(err))
+	assert.False(s.T(), errors.Is(err, ForbiddenError))
+	assert.False(s.T(), errors.Is(err, UnexpectedError))
+}
+
+func (s *errorTestSuite) TestFailedResponsesShouldMapToSentinelErrors() {
+	if testEmulator == nil {
+		s.T().Skip("failures can only be injected on the emulator")
+	}
+
+	tests := map[int]AppConfigClientError{
+		http.StatusForbidden:          ForbiddenError,
+		http.StatusUnauthorized:       UnauthorizedError,
+		http.StatusConflict:           KVLockedError,
+		http.StatusPreconditionFailed: PreconditionFailedError,
+		http.StatusBadRequest:         UnexpectedError,
+	}
+
+	for statusCode, sentinel := range tests {
+		testEmulator.Fail(1, statusCode)
+
+		_, err := s.client.ListKeys("*")
+
+		require.ErrorIs(s.T(), err, sentinel, "status %d", statusCode)
+
+		var responseError *ResponseError
+		require.True(s.T(), errors.As(err, &responseError), "status %d", statusCode)
+		assert.Equal(s.T(), statusCode, responseError.StatusCode)
+		assert.NotEmpty(s.T(), responseError.RequestID)
+		require.NotNil(s.T(), responseError.Problem, "status %d", statusCode)
+		assert.Equal(s.T(), http.StatusText(statusCode), responseError.Problem.Title)
+		assert.Equal(s.T(), statusCode, responseError.Problem.Status)
+		assert.Contains(s.T(), err.Error(), responseError.RequestID)
+	}
+}
+
+func (s *errorTestSuite) TestThrottledShouldTellWhenToRetry() {
+	if testEmulator == nil {
+		s.T().Skip("failures can only be injected on the emulator")
+	}
+
+	s.client.MaxRetries = 0
+	defer func() { s.client.MaxRetries = DefaultMaxRetries }()
+
+	testEmulator.Throttle(1, 1500*time.Millisecond)
+
+	_, err := s.client.ListKeys("*")
+
+	require.True(s.T(), IsThrottled(err))
+
+	var responseError *ResponseError
+	require.True(s.T(), errors.As(err, &responseError))
+	assert.Equal(s.T(), 1500*time.Millisecond, responseError.RetryAfter)
+}
+
+func (s *errorTestSuite) TestNotFoundShouldKeepTheResponse() {
+	_, err := s.client.GetKeyValue(LabelNone, uuid.New().String())
+
+	var responseError *ResponseError
+	require.True(s.T(), errors.As(err, &responseError))
+	assert.Equal(s.T(), http.StatusNotFound, responseError.StatusCode)
+}
+
+func (s *errorTestSuite) TestParseRetryAfter() {
+	header := http.Header{}
+	assert.Equal(s.T(), time.Duration(0), parseRetryAfter(header))
+
+	header.Set("Retry-After", "3")
+	assert.Equal(s.T(), 3*time.Second, parseRetryAfter(header))
+
+	header.Set("retry-after-ms", "1500")
+	assert.Equal(s.T(), 1500*time.Millisecond, parseRetryAfter(header))
+
+	header = http.Header{}
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.InDelta(s.T(), float64(time.Minute), float64(parseRetryAfter(header)), float64(2*time.Second))
+}
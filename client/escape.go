@@ -0,0 +1,115 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// nullLabel is how App Configuration designates the key-values without label in the label query parameter
+const nullLabel = "\x00"
+
+// EscapeFilter escapes the characters having a meaning in the key and label filters ('*', ',' and '\'),
+// so that the filter only matches the given value
+func EscapeFilter(value string) string {
+	return filterEscaper.Replace(value)
+}
+
+var filterEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `,`, `\,`)
+
+// escapePathSegment escapes a key or a name into a single path segment. '+' is escaped as well, some
+// servers decoding it as a space in paths too.
+func escapePathSegment(value string) string {
+	return strings.Replace(url.PathEscape(value), "+", "%2B", -1)
+}
+
+// labelParameter maps LabelNone, as a label or as an element of a comma-separated label filter,
+// to the null label. The other labels are sent as they are.
+func labelParameter(label string) string {
+	if label == LabelNone {
+		return nullLabel
+	}
+
+	elements := splitFilter(label)
+	if len(elements) == 1 {
+		return label
+	}
+
+	for i, element := range elements {
+		if element == LabelNone {
+			elements[i] = nullLabel
+		}
+	}
+
+	return strings.Join(elements, ",")
+}
+
+// splitFilter splits a filter on the commas which are not escaped, keeping the escape sequences as they are
+func splitFilter(filter string) []string {
+	elements := []string{}
+	current := strings.Builder{}
+	escaped := false
+	for _, c := range filter {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',':
+			elements = append(elements, current.String())
+			current.Reset()
+			continue
+		}
+
+		current.WriteRune(c)
+	}
+
+	return append(elements, current.String())
+}
+
+// withQueryParameters sets the query parameters of the request. Unlike autorest.WithQueryParameters,
+// the values are escaped as they are rather than being unescaped first, so that '%' and '+' keep their meaning.
+func withQueryParameters(queryParameters map[string]interface{}) autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+
+			query := r.URL.Query()
+			for key, value := range autorest.MapToValues(queryParameters) {
+				query[key] = value
+			}
+
+			r.URL.RawQuery = encodeQuery(query)
+
+			return r, nil
+		})
+	}
+}
+
+// encodeQuery encodes the query sorted by key, spaces being escaped as %20 rather than '+'
+func encodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, escapeQueryComponent(key)+"="+escapeQueryComponent(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func escapeQueryComponent(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEscapeTestSuite(t *testing.T) {
+	suiteTester := new(escapeTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type escapeTestSuite struct {
+	suite.Suite
+	uri    string
+	client *Client
+}
+
+// reservedCharacters are the characters having a meaning in URLs or in the App Configuration filters
+var reservedCharacters = []string{
+	" ", "/", "%", "+", "?", "#", "&", "=", ";", ":", "@", "$", "!", "'", "(", ")", "[", "]",
+	"*", ",", `\`, `"`, "<", ">", "{", "}", "|", "^", "`", "~", "%00", "%2F", "é", "日本", "🎉",
+}
+
+func (s *escapeTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *escapeTestSuite) TestKeysWithReservedCharactersShouldPass() {
+	label := uuid.New().String()
+	for _, c := range reservedCharacters {
+		s.roundTrip(uuid.New().String()+c+"end", label)
+	}
+}
+
+func (s *escapeTestSuite) TestLabelsWithReservedCharactersShouldPass() {
+	key := uuid.New().String()
+	for _, c := range reservedCharacters {
+		s.roundTrip(key, uuid.New().String()+c+"end")
+	}
+}
+
+func (s *escapeTestSuite) TestFeaturesWithReservedCharactersShouldPass() {
+	for _, c := range []string{" ", "+", "%", "?", "#", "é", ","} {
+		key := uuid.New().String() + c + "end"
+		label := uuid.New().String() + c + "end"
+
+		_, err := s.client.SetFeatureFlag(key, label, Feature{Enabled: true})
+		require.Nil(s.T(), err, "set %q", key)
+
+		result, err := s.client.GetFeature(label, key)
+		require.Nil(s.T(), err, "get %q", key)
+		assert.Equal(s.T(), key, result.Key)
+		assert.Equal(s.T(), label, result.Label)
+
+		features, err := s.client.ListFeatures(EscapeFilter(key), EscapeFilter(label))
+		require.Nil(s.T(), err, "list %q", key)
+		assert.Len(s.T(), features, 1, "list %q", key)
+
+		_, err = s.client.DeleteFeature(label, key)
+		require.Nil(s.T(), err, "delete %q", key)
+	}
+}
+
+func (s *escapeTestSuite) roundTrip(key string, label string) {
+	_, err := s.client.SetKeyValue(label, key, key)
+	require.Nil(s.T(), err, "set %q/%q", label, key)
+
+	result, err := s.client.GetKeyValue(label, key)
+	require.Nil(s.T(), err, "get %q/%q", label, key)
+	assert.Equal(s.T(), key, result.Key)
+	assert.Equal(s.T(), label, result.Label)
+	assert.Equal(s.T(), key, result.Value)
+
+	kvs, err := s.client.ListKeyValues(EscapeFilter(key), EscapeFilter(label))
+	require.Nil(s.T(), err, "list %q/%q", label, key)
+	require.Len(s.T(), kvs, 1, "list %q/%q", label, key)
+	assert.Equal(s.T(), key, kvs[0].Key)
+
+	revisions, err := s.client.ListRevisions(EscapeFilter(key), EscapeFilter(label), time.Time{}, time.Time{})
+	require.Nil(s.T(), err, "revisions %q/%q", label, key)
+	assert.Len(s.T(), revisions, 1, "revisions %q/%q", label, key)
+
+	_, err = s.client.LockKeyValue(label, key)
+	require.Nil(s.T(), err, "lock %q/%q", label, key)
+
+	_, err = s.client.UnlockKeyValue(label, key)
+	require.Nil(s.T(), err, "unlock %q/%q", label, key)
+
+	deleted, err := s.client.DeleteKeyValue(label, key)
+	require.Nil(s.T(), err, "delete %q/%q", label, key)
+	assert.True(s.T(), deleted, "delete %q/%q", label, key)
+
+	_, err = s.client.GetKeyValue(label, key)
+	assert.True(s.T(), IsNotFound(err), "get deleted %q/%q", label, key)
+}
+
+func (s *escapeTestSuite) TestEscapeFilter() {
+	assert.Equal(s.T(), `a\*b\,c\\d`, EscapeFilter(`a*b,c\d`))
+	assert.Equal(s.T(), "a b+c%00", EscapeFilter("a b+c%00"))
+}
+
+func (s *escapeTestSuite) TestLabelParameter() {
+	assert.Equal(s.T(), "\x00", labelParameter(LabelNone))
+	assert.Equal(s.T(), "dev,\x00", labelParameter("dev,"+LabelNone))
+	assert.Equal(s.T(), "a\\,%00,\x00", labelParameter(`a\,%00,`+LabelNone))
+	assert.Equal(s.T(), "50%", labelParameter("50%"))
+}
+
+func (s *escapeTestSuite) TestEscapePathSegment() {
+	assert.Equal(s.T(), "a%2Fb%2Bc%20d%3Fe%23f%25", escapePathSegment("a/b+c d?e#f%"))
+}
+
+func (s *escapeTestSuite) TestEncodeQuery() {
+	query := url.Values{"label": {"a+b c%00"}, "api-version": {"1.0"}}
+
+	assert.Equal(s.T(), "api-version=1.0&label=a%2Bb%20c%2500", encodeQuery(query))
+}
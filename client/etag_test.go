@@ -0,0 +1,113 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestETagTestSuite(t *testing.T) {
+	suiteTester := new(etagTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type etagTestSuite struct {
+	suite.Suite
+	uri    string
+	label  string
+	key    string
+	value  string
+	etag   string
+	client *Client
+}
+
+func (s *etagTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *etagTestSuite) SetupTest() {
+	s.key = uuid.New().String()
+	s.label = uuid.New().String()
+	s.value = "myValue"
+
+	result, err := s.client.SetKeyValue(s.label, s.key, s.value)
+
+	if err != nil {
+		panic("Cannot create test key-value")
+	}
+
+	s.etag = result.ETag
+}
+
+func (s *etagTestSuite) TearDownTest() {
+	_, err := s.client.DeleteKeyValue(s.label, s.key)
+
+	if err != nil {
+		panic("Cannot delete test key-value")
+	}
+}
+
+func (s *etagTestSuite) TestGetKeyValueShouldReturnETag() {
+	result, err := s.client.GetKeyValue(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.NotEmpty(s.T(), result.ETag)
+	assert.Equal(s.T(), s.etag, result.ETag)
+}
+
+func (s *etagTestSuite) TestSetKeyValueIfMatchShouldPass() {
+	result, err := s.client.SetKeyValue(s.label, s.key, "newValue", IfMatch(s.etag))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "newValue", result.Value)
+	assert.NotEqual(s.T(), s.etag, result.ETag)
+}
+
+func (s *etagTestSuite) TestSetKeyValueIfMatchOutdatedShouldFail() {
+	_, err := s.client.SetKeyValue(s.label, s.key, "otherValue")
+	require.Nil(s.T(), err)
+
+	_, err = s.client.SetKeyValue(s.label, s.key, "newValue", IfMatch(s.etag))
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsPreconditionFailed(err))
+}
+
+func (s *etagTestSuite) TestSetKeyValueIfNoneMatchExistingShouldFail() {
+	_, err := s.client.SetKeyValue(s.label, s.key, "newValue", IfNoneMatch("*"))
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsPreconditionFailed(err))
+}
+
+func (s *etagTestSuite) TestSetKeyValueIfNoneMatchNewShouldPass() {
+	key := uuid.New().String()
+
+	result, err := s.client.SetKeyValue(s.label, key, s.value, IfNoneMatch("*"))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), key, result.Key)
+
+	_, err = s.client.DeleteKeyValue(s.label, key)
+	require.Nil(s.T(), err)
+}
+
+func (s *etagTestSuite) TestDeleteKeyValueIfMatchOutdatedShouldFail() {
+	_, err := s.client.SetKeyValue(s.label, s.key, "otherValue")
+	require.Nil(s.T(), err)
+
+	_, err = s.client.DeleteKeyValue(s.label, s.key, IfMatch(s.etag))
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsPreconditionFailed(err))
+}
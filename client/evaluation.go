@@ -0,0 +1,155 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// TargetingContext identifies the user a feature flag is evaluated for, as the Microsoft.Targeting filter sees it
+type TargetingContext struct {
+	UserID string
+	Groups []string
+}
+
+// EvaluationContext holds what the filters of a feature flag are evaluated against. A zero time means now,
+// and Random, used by the Microsoft.Percentage filter, defaults to math/rand.
+type EvaluationContext struct {
+	Targeting TargetingContext
+	Time      time.Time
+	Random    func() float64
+}
+
+// Evaluate tells whether the feature flag of the given name is on in the given context, following the semantics
+// of the Microsoft Feature Management libraries: a disabled flag is off, an enabled flag without client filters is on,
+// otherwise any or all of its filters must match depending on the requirement type.
+// The filters which are not built-in cannot be evaluated and make it fail.
+func (feature Feature) Evaluate(name string, evaluation EvaluationContext) (bool, error) {
+	if !feature.Enabled {
+		return false, nil
+	}
+
+	filters := feature.Conditions.ClientFilters
+	if len(filters) == 0 {
+		return true, nil
+	}
+
+	all := feature.Conditions.RequirementType == FeatureRequirementAll
+	for _, filter := range filters {
+		on, err := filter.evaluate(name, evaluation)
+		if err != nil {
+			return false, err
+		}
+
+		if on && !all {
+			return true, nil
+		}
+		if !on && all {
+			return false, nil
+		}
+	}
+
+	return all, nil
+}
+
+func (filter FeatureFilter) evaluate(name string, evaluation EvaluationContext) (bool, error) {
+	switch filter.Name {
+	case TargetingFilterName:
+		audience, err := filter.Targeting()
+		if err != nil {
+			return false, err
+		}
+
+		return audience.isTargeted(name, evaluation.Targeting), nil
+
+	case TimeWindowFilterName:
+		if _, ok := filter.Parameters["Recurrence"]; ok {
+			return false, fmt.Errorf("the recurrence of the %s filter is not supported", TimeWindowFilterName)
+		}
+
+		start, end, err := filter.TimeWindow()
+		if err != nil {
+			return false, err
+		}
+
+		now := evaluation.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+
+		return (start.IsZero() || !now.Before(start)) && (end.IsZero() || now.Before(end)), nil
+
+	case PercentageFilterName:
+		value, err := filter.Percentage()
+		if err != nil {
+			return false, err
+		}
+
+		random := evaluation.Random
+		if random == nil {
+			random = rand.Float64
+		}
+
+		return random()*100 < float64(value), nil
+
+	default:
+		return false, fmt.Errorf("the filter %s is not built-in, it cannot be evaluated", filter.Name)
+	}
+}
+
+// isTargeted tells whether the audience includes the user, the exclusions winning over everything else,
+// and the rollout percentages applying to the hash of the user and feature name so that a user always gets the same result
+func (audience TargetingAudience) isTargeted(name string, targeting TargetingContext) bool {
+	if audience.Exclusion != nil {
+		if contains(audience.Exclusion.Users, targeting.UserID) {
+			return false
+		}
+
+		for _, group := range targeting.Groups {
+			if contains(audience.Exclusion.Groups, group) {
+				return false
+			}
+		}
+	}
+
+	if targeting.UserID != "" && contains(audience.Users, targeting.UserID) {
+		return true
+	}
+
+	for _, group := range targeting.Groups {
+		for _, targeted := range audience.Groups {
+			if targeted.Name == group && isInRollout(targeting.UserID+"\n"+name+"\n"+group, targeted.RolloutPercentage) {
+				return true
+			}
+		}
+	}
+
+	return isInRollout(targeting.UserID+"\n"+name, audience.DefaultRolloutPercentage)
+}
+
+// isInRollout places the context in [0, 100] from the first four bytes of its SHA256 hash, as the Microsoft
+// Feature Management libraries do
+func isInRollout(contextID string, percentage int) bool {
+	// the hash may place the context at exactly 100, which a rollout to everyone must still include
+	if percentage >= 100 {
+		return true
+	}
+
+	hash := sha256.Sum256([]byte(contextID))
+	marker := binary.LittleEndian.Uint32(hash[:4])
+
+	return float64(marker)/float64(math.MaxUint32)*100 < float64(percentage)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEvaluationTestSuite(t *testing.T) {
+	suiteTester := new(evaluationTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type evaluationTestSuite struct {
+	suite.Suite
+	start time.Time
+	end   time.Time
+}
+
+func (s *evaluationTestSuite) SetupSuite() {
+	s.start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.end = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *evaluationTestSuite) evaluate(feature Feature, evaluation EvaluationContext) bool {
+	on, err := feature.Evaluate("DarkMode", evaluation)
+	require.Nil(s.T(), err)
+
+	return on
+}
+
+func (s *evaluationTestSuite) targeting(audience TargetingAudience) FeatureFilter {
+	filter, err := TargetingFilter(audience)
+	require.Nil(s.T(), err)
+
+	return filter
+}
+
+func (s *evaluationTestSuite) TestEvaluateWithoutFilters() {
+	assert.True(s.T(), s.evaluate(Feature{Enabled: true}, EvaluationContext{}))
+	assert.False(s.T(), s.evaluate(Feature{Enabled: false}, EvaluationContext{}))
+}
+
+func (s *evaluationTestSuite) TestEvaluateDisabledIgnoresFilters() {
+	feature := Feature{Conditions: FeatureConditions{ClientFilters: []FeatureFilter{PercentageFilter(100)}}}
+
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{}))
+}
+
+func (s *evaluationTestSuite) TestEvaluateTimeWindow() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{TimeWindowFilter(s.start, s.end)}}}
+
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Time: s.start.Add(-time.Second)}))
+	assert.True(s.T(), s.evaluate(feature, EvaluationContext{Time: s.start}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Time: s.end}))
+}
+
+func (s *evaluationTestSuite) TestEvaluateOpenTimeWindow() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{TimeWindowFilter(time.Time{}, s.end)}}}
+
+	assert.True(s.T(), s.evaluate(feature, EvaluationContext{Time: s.start.AddDate(-10, 0, 0)}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Time: s.end.AddDate(10, 0, 0)}))
+}
+
+func (s *evaluationTestSuite) TestEvaluatePercentage() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{PercentageFilter(30)}}}
+
+	assert.True(s.T(), s.evaluate(feature, EvaluationContext{Random: func() float64 { return 0.29 }}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Random: func() float64 { return 0.3 }}))
+}
+
+func (s *evaluationTestSuite) TestEvaluateTargeting() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{s.targeting(TargetingAudience{
+		Users:  []string{"alice", "mallory"},
+		Groups: []TargetingGroup{{Name: "beta", RolloutPercentage: 100}, {Name: "ring1", RolloutPercentage: 0}},
+		Exclusion: &TargetingExclusion{
+			Users:  []string{"mallory"},
+			Groups: []string{"blocked"},
+		},
+	})}}}
+
+	assert.True(s.T(), s.evaluate(feature, EvaluationContext{Targeting: TargetingContext{UserID: "alice"}}))
+	assert.True(s.T(), s.evaluate(feature, EvaluationContext{Targeting: TargetingContext{UserID: "bob", Groups: []string{"beta"}}}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Targeting: TargetingContext{UserID: "bob", Groups: []string{"ring1"}}}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Targeting: TargetingContext{UserID: "mallory"}}))
+	assert.False(s.T(), s.evaluate(feature, EvaluationContext{Targeting: TargetingContext{UserID: "alice", Groups: []string{"blocked"}}}))
+}
+
+func (s *evaluationTestSuite) TestEvaluateTargetingRolloutIsStable() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{s.targeting(TargetingAudience{
+		DefaultRolloutPercentage: 50,
+	})}}}
+
+	on := 0
+	for _, user := range []string{"u0", "u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "u9", "u10", "u11", "u12", "u13", "u14", "u15"} {
+		evaluation := EvaluationContext{Targeting: TargetingContext{UserID: user}}
+		first := s.evaluate(feature, evaluation)
+		assert.Equal(s.T(), first, s.evaluate(feature, evaluation))
+
+		if first {
+			on++
+		}
+	}
+
+	assert.True(s.T(), on > 0 && on < 16)
+}
+
+func (s *evaluationTestSuite) TestEvaluateRequirementType() {
+	filters := []FeatureFilter{TimeWindowFilter(s.start, s.end), s.targeting(TargetingAudience{Users: []string{"alice"}})}
+	evaluation := EvaluationContext{Time: s.end.AddDate(0, 1, 0), Targeting: TargetingContext{UserID: "alice"}}
+
+	assert.True(s.T(), s.evaluate(Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: filters}}, evaluation))
+	assert.False(s.T(), s.evaluate(Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: filters, RequirementType: FeatureRequirementAll}}, evaluation))
+
+	evaluation.Time = s.start
+	assert.True(s.T(), s.evaluate(Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: filters, RequirementType: FeatureRequirementAll}}, evaluation))
+}
+
+func (s *evaluationTestSuite) TestEvaluateCustomFilterShouldFail() {
+	feature := Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: []FeatureFilter{{Name: "Contoso.Browser"}}}}
+
+	_, err := feature.Evaluate("DarkMode", EvaluationContext{})
+	assert.NotNil(s.T(), err)
+}
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Names of the feature filters built in the Microsoft Feature Management libraries
+const (
+	TargetingFilterName  = "Microsoft.Targeting"
+	TimeWindowFilterName = "Microsoft.TimeWindow"
+	PercentageFilterName = "Microsoft.Percentage"
+)
+
+// Requirement types of the client filters of a feature flag
+const (
+	FeatureRequirementAny = "Any"
+	FeatureRequirementAll = "All"
+)
+
+// Status overrides of a feature flag variant
+const (
+	VariantStatusNone     = "None"
+	VariantStatusEnabled  = "Enabled"
+	VariantStatusDisabled = "Disabled"
+)
+
+// Feature is the definition of a feature flag, stored as the value of its key-value.
+// Variants, allocation and telemetry belong to the schema of the Microsoft Feature Management v2 libraries.
+// Raw is the JSON value the feature was read from, SetFeature keeping the fields Feature does not model from it.
+// Enabled is only the state of the flag, Evaluate telling whether it is on once its filters are applied.
+type Feature struct {
+	Description string
+	Enabled     bool
+	Conditions  FeatureConditions
+	Variants    []FeatureVariant
+	Allocation  *FeatureAllocation
+	Telemetry   *FeatureTelemetry
+	Raw         json.RawMessage
+}
+
+// featureFields are the fields of a feature flag modeled by Feature, along with the ones of their own fields
+// when these are objects merged into the existing ones rather than replaced
+type featureFields map[string]featureFields
+
+var modeledFeatureFields = featureFields{
+	"id":          nil,
+	"description": nil,
+	"enabled":     nil,
+	"conditions": {
+		"client_filters":   nil,
+		"requirement_type": nil,
+	},
+	"variants": nil,
+	"allocation": {
+		"default_when_enabled":  nil,
+		"default_when_disabled": nil,
+		"user":                  nil,
+		"group":                 nil,
+		"percentile":            nil,
+		"seed":                  nil,
+	},
+	"telemetry": {
+		"enabled":  nil,
+		"metadata": nil,
+	},
+}
+
+// FeatureConditions tells when an enabled feature flag is on, no client filter meaning always.
+// The requirement type tells whether any or all of the filters must match, any being the default.
+type FeatureConditions struct {
+	ClientFilters   []FeatureFilter `json:"client_filters"`
+	RequirementType string          `json:"requirement_type,omitempty"`
+}
+
+// FeatureFilter is a client filter, evaluated by the application with the given parameters
+type FeatureFilter struct {
+	Name       string                 `json:"name"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// FeatureVariant is a variant of a feature flag, its configuration value being any JSON value
+type FeatureVariant struct {
+	Name               string      `json:"name"`
+	ConfigurationValue interface{} `json:"configuration_value,omitempty"`
+	StatusOverride     string      `json:"status_override,omitempty"`
+}
+
+// FeatureAllocation tells which variant is assigned to a user, the seed making the percentiles differ between flags
+type FeatureAllocation struct {
+	DefaultWhenEnabled  string                 `json:"default_when_enabled,omitempty"`
+	DefaultWhenDisabled string                 `json:"default_when_disabled,omitempty"`
+	User                []UserAllocation       `json:"user,omitempty"`
+	Group               []GroupAllocation      `json:"group,omitempty"`
+	Percentile          []PercentileAllocation `json:"percentile,omitempty"`
+	Seed                string                 `json:"seed,omitempty"`
+}
+
+// UserAllocation assigns a variant to the given users
+type UserAllocation struct {
+	Variant string   `json:"variant"`
+	Users   []string `json:"users"`
+}
+
+// GroupAllocation assigns a variant to the users of the given groups
+type GroupAllocation struct {
+	Variant string   `json:"variant"`
+	Groups  []string `json:"groups"`
+}
+
+// PercentileAllocation assigns a variant to the users whose percentile is in [From, To)
+type PercentileAllocation struct {
+	Variant string  `json:"variant"`
+	From    float64 `json:"from"`
+	To      float64 `json:"to"`
+}
+
+// FeatureTelemetry makes the applications emit an event each time the feature flag is evaluated
+type FeatureTelemetry struct {
+	Enabled  bool              `json:"enabled"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate checks that the variants of a feature flag have unique names, and that its allocation only
+// references them with percentiles in range
+func (feature Feature) Validate() error {
+	variants := map[string]bool{}
+	for _, variant := range feature.Variants {
+		if variant.Name == "" {
+			return fmt.Errorf("a variant requires a name")
+		}
+		if variants[variant.Name] {
+			return fmt.Errorf("the variant %s is declared more than once", variant.Name)
+		}
+		variants[variant.Name] = true
+	}
+
+	allocation := feature.Allocation
+	if allocation == nil {
+		return nil
+	}
+
+	type reference struct {
+		field   string
+		variant string
+	}
+
+	references := []reference{}
+	if allocation.DefaultWhenEnabled != "" {
+		references = append(references, reference{"default_when_enabled", allocation.DefaultWhenEnabled})
+	}
+	if allocation.DefaultWhenDisabled != "" {
+		references = append(references, reference{"default_when_disabled", allocation.DefaultWhenDisabled})
+	}
+	for _, user := range allocation.User {
+		references = append(references, reference{"user", user.Variant})
+	}
+	for _, group := range allocation.Group {
+		references = append(references, reference{"group", group.Variant})
+	}
+	for _, percentile := range allocation.Percentile {
+		references = append(references, reference{"percentile", percentile.Variant})
+
+		if percentile.From < 0 || percentile.To > 100 || percentile.From > percentile.To {
+			return fmt.Errorf("the percentile allocation [%g, %g) of the variant %s is not within [0, 100]", percentile.From, percentile.To, percentile.Variant)
+		}
+	}
+
+	for _, r := range references {
+		if !variants[r.variant] {
+			return fmt.Errorf("the %s allocation references the variant %s, which is not declared", r.field, r.variant)
+		}
+	}
+
+	return nil
+}
+
+// TargetingAudience holds the parameters of the Microsoft.Targeting filter
+type TargetingAudience struct {
+	Users                    []string            `json:"Users"`
+	Groups                   []TargetingGroup    `json:"Groups"`
+	DefaultRolloutPercentage int                 `json:"DefaultRolloutPercentage"`
+	Exclusion                *TargetingExclusion `json:"Exclusion,omitempty"`
+}
+
+// TargetingGroup rolls a feature out to a percentage of the users of a group
+type TargetingGroup struct {
+	Name              string `json:"Name"`
+	RolloutPercentage int    `json:"RolloutPercentage"`
+}
+
+// TargetingExclusion lists the users and groups a feature is never rolled out to
+type TargetingExclusion struct {
+	Users  []string `json:"Users"`
+	Groups []string `json:"Groups"`
+}
+
+type targetingParameters struct {
+	Audience TargetingAudience `json:"Audience"`
+}
+
+type percentageParameters struct {
+	Value int `json:"Value"`
+}
+
+// TargetingFilter builds a Microsoft.Targeting filter
+func TargetingFilter(audience TargetingAudience) (FeatureFilter, error) {
+	parameters, err := toParameters(targetingParameters{Audience: audience})
+	if err != nil {
+		return FeatureFilter{}, err
+	}
+
+	return FeatureFilter{Name: TargetingFilterName, Parameters: parameters}, nil
+}
+
+// TimeWindowFilter builds a Microsoft.TimeWindow filter, a zero start or end leaving the window open on that side
+func TimeWindowFilter(start time.Time, end time.Time) FeatureFilter {
+	parameters := map[string]interface{}{}
+	if !start.IsZero() {
+		parameters["Start"] = start.UTC().Format(http.TimeFormat)
+	}
+	if !end.IsZero() {
+		parameters["End"] = end.UTC().Format(http.TimeFormat)
+	}
+
+	return FeatureFilter{Name: TimeWindowFilterName, Parameters: parameters}
+}
+
+// PercentageFilter builds a Microsoft.Percentage filter, turning the feature on for the given percentage of the evaluations
+func PercentageFilter(value int) FeatureFilter {
+	return FeatureFilter{Name: PercentageFilterName, Parameters: map[string]interface{}{"Value": value}}
+}
+
+// Targeting reads the parameters of a Microsoft.Targeting filter
+func (filter FeatureFilter) Targeting() (TargetingAudience, error) {
+	parameters := targetingParameters{}
+	if err := filter.parametersAs(TargetingFilterName, &parameters); err != nil {
+		return TargetingAudience{}, err
+	}
+
+	return parameters.Audience, nil
+}
+
+// TimeWindow reads the parameters of a Microsoft.TimeWindow filter, a missing start or end being returned as a zero time
+func (filter FeatureFilter) TimeWindow() (start time.Time, end time.Time, err error) {
+	if filter.Name != TimeWindowFilterName {
+		return start, end, fmt.Errorf("the filter %s is not a %s filter", filter.Name, TimeWindowFilterName)
+	}
+
+	if start, err = parseFilterTime(filter.Parameters["Start"]); err != nil {
+		return start, end, err
+	}
+
+	end, err = parseFilterTime(filter.Parameters["End"])
+
+	return start, end, err
+}
+
+// Percentage reads the parameter of a Microsoft.Percentage filter
+func (filter FeatureFilter) Percentage() (int, error) {
+	parameters := percentageParameters{}
+	if err := filter.parametersAs(PercentageFilterName, &parameters); err != nil {
+		return 0, err
+	}
+
+	return parameters.Value, nil
+}
+
+func (filter FeatureFilter) parametersAs(name string, target interface{}) error {
+	if filter.Name != name {
+		return fmt.Errorf("the filter %s is not a %s filter", filter.Name, name)
+	}
+
+	b, err := json.Marshal(filter.Parameters)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, target)
+}
+
+func toParameters(source interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := map[string]interface{}{}
+	err = json.Unmarshal(b, &parameters)
+
+	return parameters, err
+}
+
+// parseFilterTime parses a time of a Microsoft.TimeWindow filter, which the portal writes in the RFC1123 format,
+// and the SDKs accept in the RFC3339 format as well
+func parseFilterTime(value interface{}) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid time window boundary %v", value)
+	}
+
+	if t, err := http.ParseTime(s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// mergeFeature writes the modeled fields of the payload over the raw JSON value of a feature flag, keeping the
+// other fields untouched. A raw value which is not a JSON object is replaced.
+func mergeFeature(raw json.RawMessage, payload featurePayload) ([]byte, error) {
+	desired := map[string]interface{}{}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &desired); err != nil {
+		return nil, err
+	}
+
+	current := map[string]interface{}{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &current); err != nil {
+			current = map[string]interface{}{}
+		}
+	}
+
+	return json.Marshal(mergeFields(current, desired, modeledFeatureFields))
+}
+
+// mergeFields keeps the fields of current which are not modeled, and takes the modeled ones from desired,
+// a modeled field missing from desired being removed
+func mergeFields(current map[string]interface{}, desired map[string]interface{}, fields featureFields) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, value := range current {
+		if _, modeled := fields[name]; !modeled {
+			result[name] = value
+		}
+	}
+
+	for name, nested := range fields {
+		value, ok := desired[name]
+		if !ok {
+			continue
+		}
+
+		currentObject, currentIsObject := current[name].(map[string]interface{})
+		desiredObject, desiredIsObject := value.(map[string]interface{})
+		if nested != nil && currentIsObject && desiredIsObject {
+			value = mergeFields(currentObject, desiredObject, nested)
+		}
+
+		result[name] = value
+	}
+
+	return result
+}
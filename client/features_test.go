@@ -1,126 +1,287 @@
-package client
-
-import (
-	"fmt"
-	"os"
-	"testing"
-
-	"github.com/google/uuid"
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
-	"github.com/stretchr/testify/suite"
-)
-
-func TestFeaturesTestSuite(t *testing.T) {
-	suiteTester := new(featuresTestSuite)
-	suite.Run(t, suiteTester)
-}
-
-type featuresTestSuite struct {
-	suite.Suite
-	uri         string
-	label       string
-	key         string
-	enabled     bool
-	description string
-	client      *Client
-	keyNoLabel  string
-}
-
-func (s *featuresTestSuite) SetupSuite() {
-	s.uri = "https://testlg.azconfig.io"
-	client, err := NewClientCreds(s.uri, os.Getenv("ARM_CLIENT_ID"), os.Getenv("ARM_CLIENT_SECRET"), os.Getenv("ARM_TENANT_ID"))
-
-	if err != nil {
-		panic(err)
-	}
-
-	s.client = client
-}
-
-func (s *featuresTestSuite) SetupTest() {
-	s.key = uuid.New().String()
-	s.label = uuid.New().String()
-	s.enabled = false
-	s.description = uuid.New().String()
-	s.keyNoLabel = uuid.New().String()
-
-	_, err := s.client.SetFeature(s.key, s.label, s.enabled, s.description)
-
-	if err != nil {
-		panic(fmt.Sprintf("Cannot create feature %s", s.key))
-	}
-
-	_, err = s.client.SetFeature(s.keyNoLabel, LabelNone, s.enabled, s.description)
-
-	if err != nil {
-		panic(fmt.Sprintf("Cannot create feature %s", s.key))
-	}
-}
-
-func (s *featuresTestSuite) TearDownTest() {
-	_, err := s.client.DeleteFeature(s.label, s.key)
-
-	if err != nil {
-		panic(fmt.Sprintf("Cannot delete feature %s", s.key))
-	}
-
-	_, err = s.client.DeleteFeature(LabelNone, s.keyNoLabel)
-
-	if err != nil {
-		panic(fmt.Sprintf("Cannot delete feature %s", s.key))
-	}
-}
-
-func (s *featuresTestSuite) TestFeaturesGetNonExistingFeatureShouldPass() {
-	_, err := s.client.GetFeature(s.label, "idontexist")
-
-	require.NotNil(s.T(), err)
-	require.EqualError(s.T(), err, AppConfigClientError{Message: KVNotFoundError.Message, Info: toPrefixedFeature("idontexist")}.Error())
-
-	_, err = s.client.GetFeature(LabelNone, s.key)
-
-	require.NotNil(s.T(), err)
-	require.EqualError(s.T(), err, AppConfigClientError{Message: KVNotFoundError.Message, Info: toPrefixedFeature(s.key)}.Error())
-}
-
-func (s *featuresTestSuite) TestFeaturesGetFeatureShouldPass() {
-	result, err := s.client.GetFeature(s.label, s.key)
-
-	require.Nil(s.T(), err)
-	assert.Equal(s.T(), s.key, result.Key)
-	assert.Equal(s.T(), s.label, result.Label)
-	assert.Equal(s.T(), s.enabled, result.Enabled)
-	assert.Equal(s.T(), s.description, result.Description)
-}
-
-func (s *featuresTestSuite) TestFeaturesGetFeatureNoLabelShouldPass() {
-	result, err := s.client.GetFeature(LabelNone, s.keyNoLabel)
-
-	require.Nil(s.T(), err)
-	assert.Equal(s.T(), s.keyNoLabel, result.Key)
-	assert.Equal(s.T(), "", result.Label)
-	assert.Equal(s.T(), s.enabled, result.Enabled)
-	assert.Equal(s.T(), s.description, result.Description)
-}
-
-func (s *featuresTestSuite) TestFeaturesDeleteFeatureShouldPass() {
-	ret, _ := s.client.DeleteFeature(s.label, s.key)
-	assert.Equal(s.T(), true, ret)
-
-	_, err := s.client.GetFeature(s.label, s.key)
-	require.EqualError(s.T(), err, AppConfigClientError{Message: KVNotFoundError.Message, Info: toPrefixedFeature(s.key)}.Error())
-}
-
-func (s *featuresTestSuite) TestFeaturesDeleteFeatureNoLabelShouldPass() {
-	name := uuid.New().String()
-
-	_, err := s.client.SetFeature(name, LabelNone, true, "yop")
-	require.Nil(s.T(), err)
-
-	ret, _ := s.client.DeleteFeature(LabelNone, name)
-	assert.Equal(s.T(), true, ret)
-
-	_, err = s.client.GetFeature(LabelNone, name)
-	require.EqualError(s.T(), err, AppConfigClientError{Message: KVNotFoundError.Message, Info: toPrefixedFeature(name)}.Error())
-}
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestFeaturesTestSuite(t *testing.T) {
+	suiteTester := new(featuresTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type featuresTestSuite struct {
+	suite.Suite
+	uri         string
+	label       string
+	key         string
+	enabled     bool
+	description string
+	client      *Client
+	keyNoLabel  string
+}
+
+func (s *featuresTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *featuresTestSuite) SetupTest() {
+	s.key = uuid.New().String()
+	s.label = uuid.New().String()
+	s.enabled = false
+	s.description = uuid.New().String()
+	s.keyNoLabel = uuid.New().String()
+
+	_, err := s.client.SetFeature(s.key, s.label, s.enabled, s.description)
+
+	if err != nil {
+		panic(fmt.Sprintf("Cannot create feature %s", s.key))
+	}
+
+	_, err = s.client.SetFeature(s.keyNoLabel, LabelNone, s.enabled, s.description)
+
+	if err != nil {
+		panic(fmt.Sprintf("Cannot create feature %s", s.key))
+	}
+}
+
+func (s *featuresTestSuite) TearDownTest() {
+	_, err := s.client.DeleteFeature(s.label, s.key)
+
+	if err != nil {
+		panic(fmt.Sprintf("Cannot delete feature %s", s.key))
+	}
+
+	_, err = s.client.DeleteFeature(LabelNone, s.keyNoLabel)
+
+	if err != nil {
+		panic(fmt.Sprintf("Cannot delete feature %s", s.key))
+	}
+}
+
+func (s *featuresTestSuite) TestFeaturesGetNonExistingFeatureShouldPass() {
+	_, err := s.client.GetFeature(s.label, "idontexist")
+
+	require.NotNil(s.T(), err)
+	requireClientError(s.T(), err, KVNotFoundError, toPrefixedFeature("idontexist"))
+
+	_, err = s.client.GetFeature(LabelNone, s.key)
+
+	require.NotNil(s.T(), err)
+	requireClientError(s.T(), err, KVNotFoundError, toPrefixedFeature(s.key))
+}
+
+func (s *featuresTestSuite) TestFeaturesGetFeatureShouldPass() {
+	result, err := s.client.GetFeature(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), s.key, result.Key)
+	assert.Equal(s.T(), s.label, result.Label)
+	assert.Equal(s.T(), s.enabled, result.Enabled)
+	assert.Equal(s.T(), s.description, result.Description)
+}
+
+func (s *featuresTestSuite) TestFeaturesGetFeatureNoLabelShouldPass() {
+	result, err := s.client.GetFeature(LabelNone, s.keyNoLabel)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), s.keyNoLabel, result.Key)
+	assert.Equal(s.T(), "", result.Label)
+	assert.Equal(s.T(), s.enabled, result.Enabled)
+	assert.Equal(s.T(), s.description, result.Description)
+}
+
+func (s *featuresTestSuite) TestFeaturesDeleteFeatureShouldPass() {
+	ret, _ := s.client.DeleteFeature(s.label, s.key)
+	assert.Equal(s.T(), true, ret)
+
+	_, err := s.client.GetFeature(s.label, s.key)
+	requireClientError(s.T(), err, KVNotFoundError, toPrefixedFeature(s.key))
+}
+
+func (s *featuresTestSuite) TestFeaturesDeleteFeatureNoLabelShouldPass() {
+	name := uuid.New().String()
+
+	_, err := s.client.SetFeatureFlag(name, LabelNone, Feature{Enabled: true, Description: "yop"})
+	require.Nil(s.T(), err)
+
+	ret, _ := s.client.DeleteFeature(LabelNone, name)
+	assert.Equal(s.T(), true, ret)
+
+	_, err = s.client.GetFeature(LabelNone, name)
+	requireClientError(s.T(), err, KVNotFoundError, toPrefixedFeature(name))
+}
+
+func (s *featuresTestSuite) TestFeaturesSetFeatureWithFiltersShouldPass() {
+	targeting, err := TargetingFilter(TargetingAudience{
+		Users:                    []string{"alice"},
+		Groups:                   []TargetingGroup{{Name: "beta", RolloutPercentage: 50}},
+		DefaultRolloutPercentage: 10,
+	})
+	require.Nil(s.T(), err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filters := []FeatureFilter{
+		targeting,
+		TimeWindowFilter(start, time.Time{}),
+		PercentageFilter(25),
+	}
+
+	_, err = s.client.SetFeatureFlag(s.key, s.label, Feature{Enabled: true, Conditions: FeatureConditions{ClientFilters: filters, RequirementType: FeatureRequirementAll}})
+	require.Nil(s.T(), err)
+
+	result, err := s.client.GetFeature(s.label, s.key)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result.Conditions.ClientFilters, 3)
+	assert.Equal(s.T(), FeatureRequirementAll, result.Conditions.RequirementType)
+
+	audience, err := result.Conditions.ClientFilters[0].Targeting()
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{"alice"}, audience.Users)
+	assert.Equal(s.T(), []TargetingGroup{{Name: "beta", RolloutPercentage: 50}}, audience.Groups)
+	assert.Equal(s.T(), 10, audience.DefaultRolloutPercentage)
+
+	windowStart, windowEnd, err := result.Conditions.ClientFilters[1].TimeWindow()
+	require.Nil(s.T(), err)
+	assert.True(s.T(), start.Equal(windowStart))
+	assert.True(s.T(), windowEnd.IsZero())
+
+	percentage, err := result.Conditions.ClientFilters[2].Percentage()
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 25, percentage)
+
+	_, err = result.Conditions.ClientFilters[2].Targeting()
+	assert.NotNil(s.T(), err)
+}
+
+func (s *featuresTestSuite) TestFeaturesGetFeatureWithoutRequirementTypeShouldPass() {
+	result, err := s.client.GetFeature(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "", result.Conditions.RequirementType)
+}
+
+func (s *featuresTestSuite) TestFeaturesSetFeatureWithVariantsShouldPass() {
+	feature := Feature{
+		Enabled: true,
+		Variants: []FeatureVariant{
+			{Name: "Big", ConfigurationValue: map[string]interface{}{"size": "big"}},
+			{Name: "Small", ConfigurationValue: "small", StatusOverride: VariantStatusDisabled},
+		},
+		Allocation: &FeatureAllocation{
+			DefaultWhenEnabled:  "Small",
+			DefaultWhenDisabled: "Small",
+			User:                []UserAllocation{{Variant: "Big", Users: []string{"alice"}}},
+			Percentile:          []PercentileAllocation{{Variant: "Big", From: 0, To: 10}},
+			Seed:                "seed",
+		},
+		Telemetry: &FeatureTelemetry{Enabled: true},
+	}
+
+	_, err := s.client.SetFeatureFlag(s.key, s.label, feature)
+	require.Nil(s.T(), err)
+
+	result, err := s.client.GetFeature(s.label, s.key)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), feature.Variants, result.Variants)
+	assert.Equal(s.T(), feature.Allocation, result.Allocation)
+	assert.Equal(s.T(), feature.Telemetry, result.Telemetry)
+}
+
+func (s *featuresTestSuite) TestFeatureValidate() {
+	variants := []FeatureVariant{{Name: "Big"}, {Name: "Small"}}
+
+	assert.Nil(s.T(), Feature{Variants: variants, Allocation: &FeatureAllocation{DefaultWhenEnabled: "Big"}}.Validate())
+	assert.NotNil(s.T(), Feature{Variants: []FeatureVariant{{Name: "Big"}, {Name: "Big"}}}.Validate())
+	assert.NotNil(s.T(), Feature{Variants: variants, Allocation: &FeatureAllocation{DefaultWhenDisabled: "Medium"}}.Validate())
+	assert.NotNil(s.T(), Feature{Variants: variants, Allocation: &FeatureAllocation{Group: []GroupAllocation{{Variant: "Medium", Groups: []string{"beta"}}}}}.Validate())
+	assert.NotNil(s.T(), Feature{Variants: variants, Allocation: &FeatureAllocation{Percentile: []PercentileAllocation{{Variant: "Big", From: 50, To: 20}}}}.Validate())
+}
+
+func (s *featuresTestSuite) TestFeaturesSetFeatureKeepsUnknownFieldsShouldPass() {
+	raw := `{"id":"` + s.key + `","display_name":"Beta","description":"old","enabled":false,` +
+		`"conditions":{"client_filters":[],"custom":true},` +
+		`"allocation":{"default_when_enabled":"Big","future":1},` +
+		`"telemetry":{"enabled":true,"metadata":{"owner":"team"}}}`
+	_, err := s.client.setKeyValue(context.Background(), s.label, toPrefixedFeature(s.key), raw, featureContentType)
+	require.Nil(s.T(), err)
+
+	current, err := s.client.GetFeature(s.label, s.key)
+	require.Nil(s.T(), err)
+
+	feature := current.Feature
+	feature.Enabled = true
+	feature.Telemetry = nil
+	_, err = s.client.SetFeatureFlag(s.key, s.label, feature, IfMatch(current.ETag))
+	require.Nil(s.T(), err)
+
+	result, err := s.client.GetKeyValue(s.label, toPrefixedFeature(s.key))
+	require.Nil(s.T(), err)
+	assert.JSONEq(s.T(), `{"id":"`+s.key+`","display_name":"Beta","description":"old","enabled":true,`+
+		`"conditions":{"client_filters":[],"custom":true},`+
+		`"allocation":{"default_when_enabled":"Big","future":1}}`, result.Value)
+}
+
+func (s *featuresTestSuite) TestFeaturesDecimalPercentilesShouldBeDecoded() {
+	raw := `{"id":"` + s.key + `","enabled":true,"conditions":{"client_filters":[]},` +
+		`"variants":[{"name":"Big"},{"name":"Small"}],` +
+		`"allocation":{"percentile":[{"variant":"Big","from":0,"to":33.3},{"variant":"Small","from":33.3,"to":100}]}}`
+	_, err := s.client.setKeyValue(context.Background(), s.label, toPrefixedFeature(s.key), raw, featureContentType)
+	require.Nil(s.T(), err)
+
+	result, err := s.client.GetFeature(s.label, s.key)
+	require.Nil(s.T(), err)
+	require.NotNil(s.T(), result.Allocation)
+	assert.Equal(s.T(), []PercentileAllocation{{Variant: "Big", From: 0, To: 33.3}, {Variant: "Small", From: 33.3, To: 100}}, result.Allocation.Percentile)
+	assert.Nil(s.T(), result.Validate())
+
+	features, err := s.client.ListFeatures(s.key, s.label)
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), features, 1)
+}
+
+func (s *featuresTestSuite) TestFeaturesListFeaturesShouldPass() {
+	result, err := s.client.ListFeatures(s.key, s.label)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), s.key, result[0].Key)
+	assert.Equal(s.T(), s.label, result[0].Label)
+	assert.Equal(s.T(), s.description, result[0].Description)
+	assert.Equal(s.T(), s.enabled, result[0].Enabled)
+}
+
+func (s *featuresTestSuite) TestFeaturesListFeaturesShouldSkipMalformedFlags() {
+	_, err := s.client.setKeyValue(context.Background(), s.label, toPrefixedFeature(s.key+"-malformed"), "{not json", featureContentType)
+	require.Nil(s.T(), err)
+	defer s.client.DeleteKeyValue(s.label, toPrefixedFeature(s.key+"-malformed"))
+
+	result, err := s.client.ListFeatures(s.key+"*", s.label)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), s.key, result[0].Key)
+}
+
+func (s *featuresTestSuite) TestFeaturesListFeaturesByPrefixShouldPass() {
+	result, err := s.client.ListFeatures(s.keyNoLabel[:8]+"*", LabelNone)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), s.keyNoLabel, result[0].Key)
+	assert.Equal(s.T(), "", result[0].Label)
+}
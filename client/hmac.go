@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ConnectionString holds the parts of an App Configuration access key connection string
+type ConnectionString struct {
+	Endpoint string
+	ID       string
+	Secret   string
+}
+
+// ParseConnectionString parses an access key connection string such as Endpoint=https://...;Id=...;Secret=...
+func ParseConnectionString(connectionString string) (ConnectionString, error) {
+	result := ConnectionString{}
+
+	for _, part := range strings.Split(connectionString, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		// the secret is base64 encoded, hence may end with '='
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return result, fmt.Errorf("invalid connection string part %q", part)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(keyValue[0])) {
+		case "endpoint":
+			result.Endpoint = strings.TrimSpace(keyValue[1])
+		case "id":
+			result.ID = strings.TrimSpace(keyValue[1])
+		case "secret":
+			result.Secret = strings.TrimSpace(keyValue[1])
+		}
+	}
+
+	if result.Endpoint == "" || result.ID == "" || result.Secret == "" {
+		return result, fmt.Errorf("the connection string must contain an Endpoint, an Id and a Secret")
+	}
+
+	return result, nil
+}
+
+// hmacAuthorizer signs the requests with an access key, using the App Configuration HMAC-SHA256 scheme
+type hmacAuthorizer struct {
+	id     string
+	secret []byte
+	now    func() time.Time
+}
+
+func newHmacAuthorizer(id string, secret string) (*hmacAuthorizer, error) {
+	decodedSecret, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("the access key secret is not valid base64: %+v", err)
+	}
+
+	return &hmacAuthorizer{
+		id:     id,
+		secret: decodedSecret,
+		now:    time.Now,
+	}, nil
+}
+
+// WithAuthorization implements autorest.Authorizer
+func (a *hmacAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+
+			return r, a.sign(r)
+		})
+	}
+}
+
+func (a *hmacAuthorizer) sign(r *http.Request) error {
+	body := []byte{}
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	contentHash := sha256.Sum256(body)
+	encodedContentHash := base64.StdEncoding.EncodeToString(contentHash[:])
+	date := a.now().UTC().Format(http.TimeFormat)
+
+	stringToSign := fmt.Sprintf("%s\n%s\n%s;%s;%s", strings.ToUpper(r.Method), r.URL.RequestURI(), date, r.URL.Host, encodedContentHash)
+
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+
+	r.Header.Set("x-ms-date", date)
+	r.Header.Set("x-ms-content-sha256", encodedContentHash)
+	r.Header.Set("Authorization", fmt.Sprintf("HMAC-SHA256 Credential=%s&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=%s", a.id, signature))
+
+	return nil
+}
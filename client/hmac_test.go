@@ -0,0 +1,107 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestHmacTestSuite(t *testing.T) {
+	suiteTester := new(hmacTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type hmacTestSuite struct {
+	suite.Suite
+	id       string
+	secret   string
+	server   *httptest.Server
+	requests []*http.Request
+	bodies   []string
+}
+
+func (s *hmacTestSuite) SetupTest() {
+	s.id = "myId"
+	s.secret = base64.StdEncoding.EncodeToString([]byte("mySecret"))
+	s.requests = nil
+	s.bodies = nil
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+
+		w.Header().Set("Content-Type", "application/vnd.microsoft.appconfig.kv+json")
+		fmt.Fprint(w, `{"key":"myKey","value":"myValue"}`)
+	}))
+}
+
+func (s *hmacTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *hmacTestSuite) TestParseConnectionString() {
+	cs, err := ParseConnectionString(fmt.Sprintf("Endpoint=https://testlg.azconfig.io;Id=%s;Secret=%s", s.id, s.secret))
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "https://testlg.azconfig.io", cs.Endpoint)
+	assert.Equal(s.T(), s.id, cs.ID)
+	assert.Equal(s.T(), s.secret, cs.Secret)
+}
+
+func (s *hmacTestSuite) TestParseIncompleteConnectionStringShouldFail() {
+	_, err := ParseConnectionString("Endpoint=https://testlg.azconfig.io;Id=myId")
+
+	assert.Error(s.T(), err)
+}
+
+func (s *hmacTestSuite) TestConnectionStringWithInvalidSecretShouldFail() {
+	_, err := NewClientConnectionString("Endpoint=https://testlg.azconfig.io;Id=myId;Secret=not base64")
+
+	assert.Error(s.T(), err)
+}
+
+func (s *hmacTestSuite) TestRequestsAreSigned() {
+	client, err := NewClientConnectionString(fmt.Sprintf("Endpoint=%s;Id=%s;Secret=%s", s.server.URL, s.id, s.secret))
+	require.NoError(s.T(), err)
+
+	_, err = client.SetKeyValue("myLabel", "myKey", "myValue")
+	require.NoError(s.T(), err)
+
+	_, err = client.GetKeyValue("myLabel", "myKey")
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), s.requests, 2)
+	for i, r := range s.requests {
+		s.assertSigned(r, s.bodies[i])
+	}
+}
+
+func (s *hmacTestSuite) assertSigned(r *http.Request, body string) {
+	contentHash := sha256.Sum256([]byte(body))
+	encodedContentHash := base64.StdEncoding.EncodeToString(contentHash[:])
+	assert.Equal(s.T(), encodedContentHash, r.Header.Get("x-ms-content-sha256"))
+
+	date := r.Header.Get("x-ms-date")
+	_, err := time.Parse(http.TimeFormat, date)
+	assert.NoError(s.T(), err)
+
+	secret, _ := base64.StdEncoding.DecodeString(s.secret)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{r.Method, r.URL.RequestURI(), date + ";" + r.Host + ";" + encodedContentHash}, "\n")))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	expected := fmt.Sprintf("HMAC-SHA256 Credential=%s&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=%s", s.id, signature)
+	assert.Equal(s.T(), expected, r.Header.Get("Authorization"))
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type keyPayload struct {
+	Name string `json:"name"`
+}
+
+type keyListPayload struct {
+	Items    []keyPayload `json:"items"`
+	NextLink string       `json:"@nextLink"`
+}
+
+// ListKeys lists the distinct keys matching the given name filter whatever their label, across all pages.
+// The filter accepts wildcards (e.g. "MyApp:*") and comma-separated lists, an empty one matching any key.
+func (client *Client) ListKeys(nameFilter string) ([]string, error) {
+	return client.ListKeysContext(context.Background(), nameFilter)
+}
+
+// ListKeysContext is ListKeys, the requests being canceled with the context
+func (client *Client) ListKeysContext(ctx context.Context, nameFilter string) ([]string, error) {
+	result := []string{}
+
+	queryParameters := map[string]interface{}{}
+	if nameFilter != "" {
+		queryParameters["name"] = nameFilter
+	}
+
+	err := client.list(ctx, "/keys", queryParameters, nil, func(resp *http.Response) (string, error) {
+		page := keyListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		for _, key := range page.Items {
+			result = append(result, key.Name)
+		}
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestKeysTestSuite(t *testing.T) {
+	suiteTester := new(keysTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type keysTestSuite struct {
+	suite.Suite
+	uri    string
+	prefix string
+	label  string
+	keys   []string
+	client *Client
+}
+
+func (s *keysTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *keysTestSuite) SetupTest() {
+	s.prefix = fmt.Sprintf("%s:", uuid.New().String())
+	s.label = uuid.New().String()
+	s.keys = []string{s.prefix + "one", s.prefix + "three", s.prefix + "two"}
+
+	for _, key := range s.keys {
+		if _, err := s.client.SetKeyValue(s.label, key, "value"); err != nil {
+			panic(fmt.Sprintf("Cannot create key-value %s", key))
+		}
+	}
+
+	if _, err := s.client.SetKeyValue(LabelNone, s.keys[0], "value"); err != nil {
+		panic(fmt.Sprintf("Cannot create key-value %s", s.keys[0]))
+	}
+}
+
+func (s *keysTestSuite) TearDownTest() {
+	for _, key := range s.keys {
+		if _, err := s.client.DeleteKeyValue(s.label, key); err != nil {
+			panic(fmt.Sprintf("Cannot delete key-value %s", key))
+		}
+	}
+
+	if _, err := s.client.DeleteKeyValue(LabelNone, s.keys[0]); err != nil {
+		panic(fmt.Sprintf("Cannot delete key-value %s", s.keys[0]))
+	}
+}
+
+func (s *keysTestSuite) TestListKeysByPrefixShouldReturnDistinctKeys() {
+	result, err := s.client.ListKeys(s.prefix + "*")
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), s.keys, result)
+}
+
+func (s *keysTestSuite) TestListKeysSeveralNamesShouldPass() {
+	result, err := s.client.ListKeys(fmt.Sprintf("%s,%s", s.keys[0], s.keys[2]))
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), []string{s.keys[0], s.keys[2]}, result)
+}
+
+func (s *keysTestSuite) TestListKeysNoMatchShouldReturnEmpty() {
+	result, err := s.client.ListKeys(s.prefix + "idontexist")
+
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func (s *keysTestSuite) TestListKeysSeveralPagesShouldPass() {
+	if testEmulator == nil {
+		s.T().Skip("the page size can only be changed on the emulator")
+	}
+
+	testEmulator.PageSize = 1
+	defer func() { testEmulator.PageSize = 100 }()
+
+	result, err := s.client.ListKeys(s.prefix + "*")
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), s.keys, result)
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type labelPayload struct {
+	Name *string `json:"name"`
+}
+
+type labelListPayload struct {
+	Items    []labelPayload `json:"items"`
+	NextLink string         `json:"@nextLink"`
+}
+
+// ListLabels lists the labels in use matching the given name filter, across all pages.
+// The filter accepts wildcards (e.g. "Prod*") and comma-separated lists, an empty one matching any label.
+// The key-values without label are listed as an empty label.
+func (client *Client) ListLabels(nameFilter string) ([]string, error) {
+	return client.ListLabelsContext(context.Background(), nameFilter)
+}
+
+// ListLabelsContext is ListLabels, the requests being canceled with the context
+func (client *Client) ListLabelsContext(ctx context.Context, nameFilter string) ([]string, error) {
+	result := []string{}
+
+	queryParameters := map[string]interface{}{}
+	if nameFilter != "" {
+		queryParameters["name"] = labelParameter(nameFilter)
+	}
+
+	err := client.list(ctx, "/labels", queryParameters, nil, func(resp *http.Response) (string, error) {
+		page := labelListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		for _, label := range page.Items {
+			if label.Name == nil {
+				result = append(result, "")
+			} else {
+				result = append(result, *label.Name)
+			}
+		}
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestLabelsTestSuite(t *testing.T) {
+	suiteTester := new(labelsTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type labelsTestSuite struct {
+	suite.Suite
+	uri    string
+	key    string
+	prefix string
+	labels []string
+	client *Client
+}
+
+func (s *labelsTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *labelsTestSuite) SetupTest() {
+	s.key = uuid.New().String()
+	s.prefix = uuid.New().String()
+	s.labels = []string{s.prefix + "-dev", s.prefix + "-prod", s.prefix + "-test"}
+
+	for _, label := range append(s.labels, LabelNone) {
+		if _, err := s.client.SetKeyValue(label, s.key, "value"); err != nil {
+			panic(fmt.Sprintf("Cannot create key-value %s with label %s", s.key, label))
+		}
+	}
+}
+
+func (s *labelsTestSuite) TearDownTest() {
+	for _, label := range append(s.labels, LabelNone) {
+		if _, err := s.client.DeleteKeyValue(label, s.key); err != nil {
+			panic(fmt.Sprintf("Cannot delete key-value %s with label %s", s.key, label))
+		}
+	}
+}
+
+func (s *labelsTestSuite) TestListLabelsByPrefixShouldPass() {
+	result, err := s.client.ListLabels(s.prefix + "*")
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), s.labels, result)
+}
+
+func (s *labelsTestSuite) TestListLabelsSeveralNamesShouldPass() {
+	result, err := s.client.ListLabels(fmt.Sprintf("%s,%s", s.labels[0], s.labels[2]))
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), []string{s.labels[0], s.labels[2]}, result)
+}
+
+func (s *labelsTestSuite) TestListLabelsNoLabelShouldPass() {
+	result, err := s.client.ListLabels(LabelNone)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{""}, result)
+}
+
+func (s *labelsTestSuite) TestListLabelsSeveralPagesShouldPass() {
+	if testEmulator == nil {
+		s.T().Skip("the page size can only be changed on the emulator")
+	}
+
+	testEmulator.PageSize = 1
+	defer func() { testEmulator.PageSize = 100 }()
+
+	result, err := s.client.ListLabels(s.prefix + "*")
+
+	require.Nil(s.T(), err)
+	assert.ElementsMatch(s.T(), s.labels, result)
+}
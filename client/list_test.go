@@ -0,0 +1,125 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestListKeyValuesTestSuite(t *testing.T) {
+	suiteTester := new(listKeyValuesTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type listKeyValuesTestSuite struct {
+	suite.Suite
+	uri    string
+	prefix string
+	label  string
+	keys   []string
+	client *Client
+}
+
+func (s *listKeyValuesTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *listKeyValuesTestSuite) SetupTest() {
+	s.prefix = fmt.Sprintf("%s:", uuid.New().String())
+	s.label = uuid.New().String()
+	s.keys = []string{s.prefix + "one", s.prefix + "two", s.prefix + "three"}
+
+	for _, key := range s.keys {
+		if _, err := s.client.SetKeyValue(s.label, key, "value"); err != nil {
+			panic(fmt.Sprintf("Cannot create key-value %s", key))
+		}
+	}
+
+	if _, err := s.client.SetKeyValue(LabelNone, s.keys[0], "value"); err != nil {
+		panic(fmt.Sprintf("Cannot create key-value %s", s.keys[0]))
+	}
+}
+
+func (s *listKeyValuesTestSuite) TearDownTest() {
+	for _, key := range s.keys {
+		if _, err := s.client.DeleteKeyValue(s.label, key); err != nil {
+			panic(fmt.Sprintf("Cannot delete key-value %s", key))
+		}
+	}
+
+	if _, err := s.client.DeleteKeyValue(LabelNone, s.keys[0]); err != nil {
+		panic(fmt.Sprintf("Cannot delete key-value %s", s.keys[0]))
+	}
+}
+
+func (s *listKeyValuesTestSuite) TestListKeyValuesByPrefixAndLabelShouldPass() {
+	result, err := s.client.ListKeyValues(s.prefix+"*", s.label)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, len(s.keys))
+	for _, kv := range result {
+		assert.Contains(s.T(), s.keys, kv.Key)
+		assert.Equal(s.T(), s.label, kv.Label)
+		assert.Equal(s.T(), "value", kv.Value)
+	}
+}
+
+func (s *listKeyValuesTestSuite) TestListKeyValuesNoLabelShouldPass() {
+	result, err := s.client.ListKeyValues(s.prefix+"*", LabelNone)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), s.keys[0], result[0].Key)
+	assert.Equal(s.T(), "", result[0].Label)
+}
+
+func (s *listKeyValuesTestSuite) TestListKeyValuesSeveralLabelsShouldPass() {
+	result, err := s.client.ListKeyValues(s.keys[0], fmt.Sprintf("%s,%s", s.label, LabelNone))
+
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), result, 2)
+}
+
+func (s *listKeyValuesTestSuite) TestListKeyValuesNoMatchShouldReturnEmpty() {
+	result, err := s.client.ListKeyValues(s.prefix+"*", "idontexist")
+
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func (s *listKeyValuesTestSuite) TestListKeyValuesSeveralPagesShouldPass() {
+	if testEmulator == nil {
+		s.T().Skip("the page size can only be changed on the emulator")
+	}
+
+	testEmulator.PageSize = 1
+	defer func() { testEmulator.PageSize = 100 }()
+
+	result, err := s.client.ListKeyValues(s.prefix+"*", "*")
+
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), result, len(s.keys)+1)
+}
+
+func (s *listKeyValuesTestSuite) TestNextLinkShouldParseLinkHeader() {
+	assert.Equal(s.T(), "/kv?after=abc&api-version=1.0", nextLink(`</kv?after=abc&api-version=1.0>; rel="next"`))
+	assert.Equal(s.T(), "", nextLink(`</kv?after=abc>; rel="prev"`))
+	assert.Equal(s.T(), "", nextLink(""))
+}
+
+func (s *listKeyValuesTestSuite) TestNextLinkShouldKeepRawCommasOfTheTarget() {
+	assert.Equal(s.T(), "/kv?label=a,b&key=x\\,y&after=abc", nextLink(`</kv?label=a,b&key=x\,y&after=abc>; rel="next"`))
+	assert.Equal(s.T(), "/kv?label=a,b&after=def", nextLink(`</kv?label=a,b&after=abc>; rel="prev", </kv?label=a,b&after=def>; rel="next"`))
+	assert.Equal(s.T(), "/kv?after=def", nextLink(`</kv?after=abc>; title="a, b"; rel="prev", </kv?after=def>; rel="next"`))
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestLockTestSuite(t *testing.T) {
+	suiteTester := new(lockTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type lockTestSuite struct {
+	suite.Suite
+	uri    string
+	label  string
+	key    string
+	value  string
+	client *Client
+}
+
+func (s *lockTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *lockTestSuite) SetupTest() {
+	s.key = uuid.New().String()
+	s.label = uuid.New().String()
+	s.value = "myValue"
+
+	_, err := s.client.SetKeyValue(s.label, s.key, s.value)
+
+	if err != nil {
+		panic("Cannot create test key-value")
+	}
+}
+
+func (s *lockTestSuite) TearDownTest() {
+	_, err := s.client.UnlockKeyValue(s.label, s.key)
+
+	if err != nil {
+		panic("Cannot unlock test key-value")
+	}
+
+	_, err = s.client.DeleteKeyValue(s.label, s.key)
+
+	if err != nil {
+		panic("Cannot delete test key-value")
+	}
+}
+
+func (s *lockTestSuite) TestLockKeyValueShouldPass() {
+	result, err := s.client.LockKeyValue(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.True(s.T(), result.Locked)
+
+	result, err = s.client.GetKeyValue(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.True(s.T(), result.Locked)
+}
+
+func (s *lockTestSuite) TestSetLockedKeyValueShouldFail() {
+	_, err := s.client.LockKeyValue(s.label, s.key)
+	require.Nil(s.T(), err)
+
+	_, err = s.client.SetKeyValue(s.label, s.key, "newValue")
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsLocked(err))
+}
+
+func (s *lockTestSuite) TestUnlockKeyValueShouldPass() {
+	_, err := s.client.LockKeyValue(s.label, s.key)
+	require.Nil(s.T(), err)
+
+	result, err := s.client.UnlockKeyValue(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.False(s.T(), result.Locked)
+
+	_, err = s.client.SetKeyValue(s.label, s.key, "newValue")
+	require.Nil(s.T(), err)
+}
+
+func (s *lockTestSuite) TestLockKeyValueIfMatchOutdatedShouldFail() {
+	result, err := s.client.SetKeyValue(s.label, s.key, "newValue")
+	require.Nil(s.T(), err)
+
+	_, err = s.client.SetKeyValue(s.label, s.key, "otherValue")
+	require.Nil(s.T(), err)
+
+	_, err = s.client.LockKeyValue(s.label, s.key, IfMatch(result.ETag))
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsPreconditionFailed(err))
+}
+
+func (s *lockTestSuite) TestLockNonExistingKeyValueShouldFail() {
+	_, err := s.client.LockKeyValue(s.label, "idontexist")
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsNotFound(err))
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/arkiaconsulting/terraform-provider-akc/emulator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEndpoint is the store the tests run against: an in-process emulator, unless AKC_TEST_ENDPOINT points to another
+// store, in which case AKC_CONNECTION_STRING or the ARM_* variables must hold credentials allowed to use it
+var testEndpoint string
+
+var testEmulator *emulator.Server
+
+func TestMain(m *testing.M) {
+	testEndpoint = os.Getenv("AKC_TEST_ENDPOINT")
+	if testEndpoint == "" {
+		testEmulator = emulator.NewServer()
+		testEndpoint = testEmulator.URL
+	}
+
+	code := m.Run()
+
+	if testEmulator != nil {
+		testEmulator.Close()
+	}
+
+	os.Exit(code)
+}
+
+func newTestClient(endpoint string) (*Client, error) {
+	if testEmulator != nil {
+		return NewClient(endpoint, autorest.NullAuthorizer{})
+	}
+
+	if connectionString := os.Getenv("AKC_CONNECTION_STRING"); connectionString != "" {
+		return NewClientConnectionString(connectionString)
+	}
+
+	return NewClientCreds(endpoint, os.Getenv("ARM_CLIENT_ID"), os.Getenv("ARM_CLIENT_SECRET"), os.Getenv("ARM_TENANT_ID"))
+}
+
+// requireClientError asserts that the error is an instance of the sentinel error, about the given item
+func requireClientError(t *testing.T, err error, sentinel AppConfigClientError, info string) {
+	require.ErrorIs(t, err, sentinel)
+
+	var clientError AppConfigClientError
+	require.True(t, errors.As(err, &clientError))
+	assert.Equal(t, info, clientError.Info)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// RequestOption customizes a single request sent to App Configuration, usually by adding a header
+type RequestOption func(headers map[string]interface{})
+
+// WithHeader adds the given header to the request
+func WithHeader(name string, value string) RequestOption {
+	return func(headers map[string]interface{}) {
+		headers[name] = value
+	}
+}
+
+// IfMatch makes the request succeed only if the key-value still has the given ETag.
+// An empty ETag leaves the request unconditional.
+func IfMatch(etag string) RequestOption {
+	return func(headers map[string]interface{}) {
+		if etag != "" {
+			headers["If-Match"] = quoteETag(etag)
+		}
+	}
+}
+
+// IfNoneMatch makes the request succeed only if the key-value does not have the given ETag.
+// Use "*" to make it succeed only if the key-value does not exist.
+func IfNoneMatch(etag string) RequestOption {
+	return func(headers map[string]interface{}) {
+		if etag != "" {
+			headers["If-None-Match"] = quoteETag(etag)
+		}
+	}
+}
+
+// AsOf makes the request return the key-values as they were at the given time, through the Accept-Datetime header
+func AsOf(t time.Time) RequestOption {
+	return func(headers map[string]interface{}) {
+		headers["Accept-Datetime"] = t.UTC().Format(http.TimeFormat)
+	}
+}
+
+func withOptions(options []RequestOption) autorest.PrepareDecorator {
+	headers := map[string]interface{}{}
+	for _, option := range options {
+		option(headers)
+	}
+
+	if len(headers) == 0 {
+		return autorest.WithNothing()
+	}
+
+	return autorest.WithHeaders(headers)
+}
+
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, "\"") {
+		return etag
+	}
+
+	return fmt.Sprintf("\"%s\"", etag)
+}
+
+func unquoteETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
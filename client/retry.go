@@ -0,0 +1,250 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a throttled or transiently failed request is retried by default
+	DefaultMaxRetries = 5
+	// DefaultRetryMaxWait is the longest wait between two attempts by default
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// retryBaseDelay is the wait before the first retry, doubled on each of the next ones
+var retryBaseDelay = 500 * time.Millisecond
+
+// retriableStatusCodes are the status codes of the failures which may not happen again
+var retriableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// withRetries retries the requests which are throttled or fail transiently, up to MaxRetries times.
+// The wait between two attempts is the one the service asks for with retry-after-ms or Retry-After, or an
+// exponential backoff with jitter otherwise. The last failure is returned when the service asks to wait for
+// longer than RetryMaxWait, or when the wait would go beyond the deadline of the request context.
+// A request whose response was lost is only sent again when it is replayable, its first attempt having maybe
+// been carried out.
+func (client *Client) withRetries() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			ctx := r.Context()
+			rr := autorest.NewRetriableRequest(r)
+
+			for attempt := 0; ; attempt++ {
+				if err := rr.Prepare(); err != nil {
+					return nil, err
+				}
+
+				resp, err := s.Do(rr.Request())
+				if attempt >= client.MaxRetries || !isRetriable(resp, err) || (err != nil && !isReplayable(r)) {
+					return resp, err
+				}
+
+				if tracker, ok := ctx.Value(replayKey{}).(*replay); ok && err != nil {
+					tracker.happened = true
+				}
+
+				delay, ok := client.retryDelay(attempt, resp)
+				if !ok {
+					return resp, err
+				}
+
+				if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+					return resp, err
+				}
+
+				if resp != nil {
+					io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+		})
+	}
+}
+
+// isRetriable tells whether the failure may not happen again, the cancellation of the request being final
+func isRetriable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	for _, statusCode := range retriableStatusCodes {
+		if resp.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replay records whether a request was sent again after an attempt whose response was lost
+type replay struct {
+	happened bool
+}
+
+type replayKey struct{}
+
+type createKey struct{}
+
+// asCreate marks the requests sent with the context as creations, which fail when the item exists even though
+// they have no If-None-Match header, the way snapshots are created
+func asCreate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, createKey{}, true)
+}
+
+// isConditionalWrite tells whether the request fails when replayed after its first attempt succeeded
+func isConditionalWrite(r *http.Request) bool {
+	if r.Method != http.MethodPut {
+		return false
+	}
+
+	created, _ := r.Context().Value(createKey{}).(bool)
+
+	return created || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Match") != ""
+}
+
+// isReplayable tells whether the request can be sent again after an attempt whose response was lost. The conditional
+// writes are only replayable when the sender tracks the replays, to resolve the failures the lost attempt may cause.
+func isReplayable(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	case http.MethodPut:
+		if isConditionalWrite(r) {
+			_, tracked := r.Context().Value(replayKey{}).(*replay)
+			return tracked
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// resolveReplayedWrite reads the item again when a conditional write fails after it was replayed, since its lost
+// attempt may have succeeded. The item read is the response of the write when it holds what was sent, the failure
+// being returned otherwise.
+func (client *Client) resolveReplayedWrite(req *http.Request, sent []byte, failure *http.Response) *http.Response {
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, req.URL.String(), nil)
+	if err != nil {
+		return failure
+	}
+
+	resp, err := client.Send(get, client.withRetries())
+	if err != nil {
+		return failure
+	}
+	defer resp.Body.Close()
+
+	current, err := ioutil.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || !containsJSON(current, sent) {
+		return failure
+	}
+
+	failure.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(current))
+
+	return resp
+}
+
+// keepBody reads the body of the request, which is kept to be sent again
+func keepBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return body, nil
+}
+
+// containsJSON tells whether every field of the sent JSON document has the same value in the current one
+func containsJSON(current []byte, sent []byte) bool {
+	var c, s interface{}
+	if json.Unmarshal(current, &c) != nil || json.Unmarshal(sent, &s) != nil {
+		return false
+	}
+
+	return jsonContains(c, s)
+}
+
+func jsonContains(current interface{}, sent interface{}) bool {
+	switch s := sent.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range s {
+			if !jsonContains(c[key], value) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(s) {
+			return false
+		}
+		for i := range s {
+			if !jsonContains(c[i], s[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(current, sent)
+	}
+}
+
+// retryDelay is the wait before the next attempt, which is not to be made when the service asks to wait
+// for longer than RetryMaxWait
+func (client *Client) retryDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if delay := parseRetryAfter(resp.Header); delay > 0 {
+			return delay, delay <= client.RetryMaxWait
+		}
+	}
+
+	backoff := client.RetryMaxWait
+	if attempt < 30 && retryBaseDelay<<uint(attempt) < backoff {
+		backoff = retryBaseDelay << uint(attempt)
+	}
+
+	// equal jitter: between half the backoff and the whole of it
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), true
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRetryTestSuite(t *testing.T) {
+	suiteTester := new(retryTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type retryTestSuite struct {
+	suite.Suite
+	uri       string
+	client    *Client
+	baseDelay time.Duration
+}
+
+func (s *retryTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+	s.baseDelay = retryBaseDelay
+	retryBaseDelay = 10 * time.Millisecond
+}
+
+func (s *retryTestSuite) TearDownSuite() {
+	retryBaseDelay = s.baseDelay
+}
+
+func (s *retryTestSuite) SetupTest() {
+	if testEmulator == nil {
+		s.T().Skip("failures can only be injected on the emulator")
+	}
+
+	s.client.MaxRetries = DefaultMaxRetries
+	s.client.RetryMaxWait = DefaultRetryMaxWait
+}
+
+func (s *retryTestSuite) TestThrottledRequestShouldBeRetriedAfterTheGivenDelay() {
+	testEmulator.Throttle(2, 100*time.Millisecond)
+
+	start := time.Now()
+	_, err := s.client.ListKeys("*")
+
+	require.Nil(s.T(), err)
+	assert.True(s.T(), time.Since(start) >= 200*time.Millisecond, "the retry-after-ms delay was not honored")
+}
+
+func (s *retryTestSuite) TestTransientFailureShouldBeRetried() {
+	testEmulator.Fail(2, http.StatusServiceUnavailable)
+
+	_, err := s.client.ListKeys("*")
+
+	require.Nil(s.T(), err)
+}
+
+func (s *retryTestSuite) TestClientErrorShouldNotBeRetried() {
+	testEmulator.Fail(1, http.StatusForbidden)
+
+	_, err := s.client.ListKeys("*")
+
+	assert.True(s.T(), IsForbidden(err))
+}
+
+func (s *retryTestSuite) TestRetriesShouldStopAfterMaxRetries() {
+	s.client.MaxRetries = 2
+	testEmulator.Throttle(3, 10*time.Millisecond)
+
+	_, err := s.client.ListKeys("*")
+
+	assert.True(s.T(), IsThrottled(err))
+}
+
+func (s *retryTestSuite) TestRetryAfterBeyondMaxWaitShouldNotBeWaited() {
+	s.client.RetryMaxWait = time.Second
+	testEmulator.Throttle(1, 5*time.Second)
+
+	start := time.Now()
+	_, err := s.client.ListKeys("*")
+
+	assert.True(s.T(), IsThrottled(err))
+	assert.True(s.T(), time.Since(start) < time.Second)
+}
+
+func (s *retryTestSuite) TestRetryAfterBeyondDeadlineShouldNotBeWaited() {
+	testEmulator.Throttle(1, 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.uri+"/keys?api-version=1.0", nil)
+	require.Nil(s.T(), err)
+
+	start := time.Now()
+	resp, err := s.client.Send(req, s.client.withRetries())
+
+	require.Nil(s.T(), err)
+	resp.Body.Close()
+	assert.Equal(s.T(), http.StatusTooManyRequests, resp.StatusCode)
+	assert.True(s.T(), time.Since(start) < time.Second)
+}
+
+func (s *retryTestSuite) TestNetworkErrorShouldBeRetried() {
+	attempts := 0
+	sender := autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection reset by peer")
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, s.uri, nil)
+	resp, err := s.client.withRetries()(sender).Do(req)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), 3, attempts)
+}
+
+func (s *retryTestSuite) TestCanceledRequestShouldNotBeRetried() {
+	attempts := 0
+	sender := autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+
+		return nil, context.Canceled
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, s.uri, nil)
+	_, err := s.client.withRetries()(sender).Do(req)
+
+	assert.True(s.T(), errors.Is(err, context.Canceled))
+	assert.Equal(s.T(), 1, attempts)
+}
+
+func (s *retryTestSuite) TestRetryDelayShouldGrowUpToMaxWait() {
+	s.client.RetryMaxWait = 100 * time.Millisecond
+
+	for attempt := 0; attempt < 40; attempt++ {
+		delay, ok := s.client.retryDelay(attempt, nil)
+
+		require.True(s.T(), ok)
+		assert.True(s.T(), delay <= s.client.RetryMaxWait, "attempt %d waits %s", attempt, delay)
+		assert.True(s.T(), delay >= 5*time.Millisecond, "attempt %d waits %s", attempt, delay)
+	}
+}
+
+func (s *retryTestSuite) TestCanceledContextShouldStopWaitingForRetry() {
+	testEmulator.Throttle(1, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := s.client.ListKeysContext(ctx, "*")
+
+	assert.True(s.T(), errors.Is(err, context.Canceled))
+	assert.True(s.T(), time.Since(start) < time.Second)
+}
+
+func (s *retryTestSuite) TestCanceledContextShouldNotSendRequests() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.client.GetKeyValueContext(ctx, LabelNone, "key")
+
+	assert.True(s.T(), errors.Is(err, context.Canceled))
+	assert.False(s.T(), IsNotFound(err))
+}
+
+type countingAuthorizer struct {
+	count int
+}
+
+func (a *countingAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			a.count++
+			return p.Prepare(r)
+		})
+	}
+}
+
+func (s *retryTestSuite) TestEachAttemptShouldBeAuthorizedOnce() {
+	authorizer := &countingAuthorizer{}
+	client, err := NewClient(s.uri, authorizer)
+	require.Nil(s.T(), err)
+
+	attempts := 0
+	client.Sender = autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		return testEmulator.Client().Do(r)
+	})
+
+	testEmulator.Fail(2, http.StatusServiceUnavailable)
+	_, err = client.ListKeys("*")
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 3, attempts)
+	assert.Equal(s.T(), attempts, authorizer.count)
+}
+
+func (s *retryTestSuite) TestLostResponseOfConditionalCreateShouldBeResolved() {
+	key := uuid.New().String()
+	testEmulator.DropAfterCommit(1)
+
+	result, err := s.client.SetKeyValue(LabelNone, key, "value", IfNoneMatch("*"))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "value", result.Value)
+	assert.NotEmpty(s.T(), result.ETag)
+}
+
+func (s *retryTestSuite) TestLostResponseOfConditionalUpdateShouldBeResolved() {
+	key := uuid.New().String()
+	current, err := s.client.SetKeyValue(LabelNone, key, "value")
+	require.Nil(s.T(), err)
+	testEmulator.DropAfterCommit(1)
+
+	result, err := s.client.SetKeyValue(LabelNone, key, "new value", IfMatch(current.ETag))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "new value", result.Value)
+}
+
+func (s *retryTestSuite) TestLostResponseOfSnapshotCreateShouldBeResolved() {
+	name := uuid.New().String()
+	testEmulator.DropAfterCommit(1)
+
+	snapshot, err := s.client.CreateSnapshot(name, []SnapshotFilter{{Key: "*"}}, SnapshotCompositionKey, 3600, map[string]string{"tag": "value"}, time.Minute)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), name, snapshot.Name)
+}
+
+func (s *retryTestSuite) TestLostResponseOfPatchShouldNotBeReplayed() {
+	name := uuid.New().String()
+	_, err := s.client.CreateSnapshot(name, []SnapshotFilter{{Key: "*"}}, SnapshotCompositionKey, 3600, nil, time.Minute)
+	require.Nil(s.T(), err)
+	testEmulator.DropAfterCommit(1)
+
+	_, err = s.client.ArchiveSnapshot(name)
+
+	assert.NotNil(s.T(), err)
+	snapshot, err := s.client.GetSnapshot(name)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), SnapshotStatusArchived, snapshot.Status)
+}
+
+func (s *retryTestSuite) TestNetworkErrorShouldOnlyReplayIdempotentRequests() {
+	tests := map[string]bool{
+		http.MethodGet:    true,
+		http.MethodDelete: true,
+		http.MethodPut:    true,
+		http.MethodPatch:  false,
+		http.MethodPost:   false,
+	}
+
+	for method, replayed := range tests {
+		attempts := 0
+		sender := autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errors.New("connection reset by peer")
+		})
+
+		req, _ := http.NewRequest(method, s.uri, nil)
+		s.client.MaxRetries = 1
+		s.client.withRetries()(sender).Do(req)
+
+		assert.Equal(s.T(), replayed, attempts == 2, method)
+	}
+
+	attempts := 0
+	sender := autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, errors.New("connection reset by peer")
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, s.uri, nil)
+	req.Header.Set("If-None-Match", "*")
+	s.client.withRetries()(sender).Do(req)
+
+	assert.Equal(s.T(), 1, attempts, "a conditional create should not be replayed when the replays are not tracked")
+}
+
+func (s *retryTestSuite) TestContainsJSON() {
+	current := `{"key":"k","value":"v","tags":{"a":"b"},"filters":[{"key":"*","label":""}],"etag":"e"}`
+
+	assert.True(s.T(), containsJSON([]byte(current), []byte(`{"value":"v","filters":[{"key":"*"}]}`)))
+	assert.False(s.T(), containsJSON([]byte(current), []byte(`{"value":"other"}`)))
+	assert.False(s.T(), containsJSON([]byte(current), []byte(`{"filters":[{"key":"*"},{"key":"a"}]}`)))
+	assert.False(s.T(), containsJSON([]byte(current), nil))
+}
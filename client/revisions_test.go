@@ -0,0 +1,109 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRevisionsTestSuite(t *testing.T) {
+	suiteTester := new(revisionsTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type revisionsTestSuite struct {
+	suite.Suite
+	uri    string
+	label  string
+	key    string
+	values []string
+	client *Client
+}
+
+func (s *revisionsTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *revisionsTestSuite) SetupTest() {
+	s.key = uuid.New().String()
+	s.label = uuid.New().String()
+	s.values = []string{"first", "second", "third"}
+
+	for _, value := range s.values {
+		if _, err := s.client.SetKeyValue(s.label, s.key, value); err != nil {
+			panic("Cannot create test key-value")
+		}
+	}
+}
+
+func (s *revisionsTestSuite) TearDownTest() {
+	_, err := s.client.DeleteKeyValue(s.label, s.key)
+
+	if err != nil {
+		panic("Cannot delete test key-value")
+	}
+}
+
+func (s *revisionsTestSuite) TestListRevisionsShouldPass() {
+	result, err := s.client.ListRevisions(s.key, s.label, time.Time{}, time.Time{})
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, len(s.values))
+	assert.Equal(s.T(), "third", result[0].Value)
+	assert.Equal(s.T(), "first", result[2].Value)
+	for _, revision := range result {
+		assert.Equal(s.T(), s.key, revision.Key)
+		assert.Equal(s.T(), s.label, revision.Label)
+	}
+}
+
+func (s *revisionsTestSuite) TestListRevisionsInTheFutureShouldReturnEmpty() {
+	result, err := s.client.ListRevisions(s.key, s.label, time.Now().Add(time.Hour), time.Time{})
+
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func (s *revisionsTestSuite) TestListRevisionsInThePastShouldReturnEmpty() {
+	result, err := s.client.ListRevisions(s.key, s.label, time.Time{}, time.Now().Add(-time.Hour))
+
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func (s *revisionsTestSuite) TestListRevisionsOtherLabelShouldReturnEmpty() {
+	result, err := s.client.ListRevisions(s.key, LabelNone, time.Time{}, time.Time{})
+
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), result)
+}
+
+func (s *revisionsTestSuite) TestGetKeyValueAsOfShouldReturnPastValue() {
+	time.Sleep(time.Second)
+	asOf := time.Now()
+	time.Sleep(time.Second)
+
+	_, err := s.client.SetKeyValue(s.label, s.key, "fourth")
+	require.Nil(s.T(), err)
+
+	result, err := s.client.GetKeyValue(s.label, s.key, AsOf(asOf))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "third", result.Value)
+
+	result, err = s.client.GetKeyValue(s.label, s.key)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "fourth", result.Value)
+}
@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// Snapshots are only available from this version of the API onwards
+const snapshotsAPIVersion = "2023-10-01"
+
+var (
+	// SnapshotCompositionKey keeps one key-value per key, the last label of the filters winning
+	SnapshotCompositionKey = "key"
+	// SnapshotCompositionKeyLabel keeps one key-value per key and label
+	SnapshotCompositionKeyLabel = "key_label"
+)
+
+// Statuses of a snapshot
+var (
+	SnapshotStatusProvisioning = "provisioning"
+	SnapshotStatusReady        = "ready"
+	SnapshotStatusArchived     = "archived"
+	SnapshotStatusFailed       = "failed"
+)
+
+const snapshotPollingDelay = 2 * time.Second
+
+type createSnapshotPayload struct {
+	Filters         []SnapshotFilter  `json:"filters"`
+	CompositionType string            `json:"composition_type,omitempty"`
+	RetentionPeriod int               `json:"retention_period,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+type updateSnapshotPayload struct {
+	Status string `json:"status"`
+}
+
+type snapshotListPayload struct {
+	Items    []SnapshotResponse `json:"items"`
+	NextLink string             `json:"@nextLink"`
+}
+
+type operationPayload struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CreateSnapshot creates a snapshot of the key-values matching the given filters, and waits for it to be ready.
+// A zero retention period lets App Configuration apply its default one.
+func (client *Client) CreateSnapshot(name string, filters []SnapshotFilter, compositionType string, retentionPeriod int, tags map[string]string, timeout time.Duration) (SnapshotResponse, error) {
+	return client.CreateSnapshotContext(context.Background(), name, filters, compositionType, retentionPeriod, tags, timeout)
+}
+
+// CreateSnapshotContext is CreateSnapshot, the requests being canceled with the context
+func (client *Client) CreateSnapshotContext(ctx context.Context, name string, filters []SnapshotFilter, compositionType string, retentionPeriod int, tags map[string]string, timeout time.Duration) (SnapshotResponse, error) {
+	payloadFilters := []SnapshotFilter{}
+	for _, filter := range filters {
+		// no label is expressed by omitting the label of the filter
+		if filter.Label == LabelNone {
+			filter.Label = ""
+		}
+		payloadFilters = append(payloadFilters, filter)
+	}
+
+	payload := createSnapshotPayload{
+		Filters:         payloadFilters,
+		CompositionType: compositionType,
+		RetentionPeriod: retentionPeriod,
+		Tags:            tags,
+	}
+
+	resp, err := client.sendPrepared(asCreate(ctx), client.getSnapshotPreparer(
+		name,
+		autorest.AsContentType("application/vnd.microsoft.appconfig.snapshot+json"),
+		autorest.AsPut(),
+		autorest.WithJSON(payload),
+	))
+	if IsLocked(err) {
+		return SnapshotResponse{}, SnapshotExistsError.from(name, err)
+	}
+	if err != nil {
+		return SnapshotResponse{}, err
+	}
+	resp.Body.Close()
+
+	if operation := resp.Header.Get("Operation-Location"); operation != "" {
+		if err := client.waitForOperation(ctx, operation, timeout); err != nil {
+			return SnapshotResponse{}, err
+		}
+	}
+
+	return client.GetSnapshotContext(ctx, name)
+}
+
+// GetSnapshot gets a snapshot
+func (client *Client) GetSnapshot(name string) (SnapshotResponse, error) {
+	return client.GetSnapshotContext(context.Background(), name)
+}
+
+// GetSnapshotContext is GetSnapshot, the requests being canceled with the context
+func (client *Client) GetSnapshotContext(ctx context.Context, name string) (SnapshotResponse, error) {
+	result := SnapshotResponse{}
+	resp, err := client.sendPrepared(ctx, client.getSnapshotPreparer(
+		name,
+		autorest.AsGet(),
+	))
+	if err != nil {
+		return result, err
+	}
+
+	if err = getJSON(resp, &result); err != nil {
+		return result, UnexpectedError.wrap(err)
+	}
+
+	result.ETag = etagOf(resp, result.ETag)
+
+	return result, nil
+}
+
+// ListSnapshots lists the snapshots matching the given name filter (wildcards allowed) and statuses, across all pages.
+// An empty filter matches any snapshot.
+func (client *Client) ListSnapshots(nameFilter string, statuses []string) ([]SnapshotResponse, error) {
+	return client.ListSnapshotsContext(context.Background(), nameFilter, statuses)
+}
+
+// ListSnapshotsContext is ListSnapshots, the requests being canceled with the context
+func (client *Client) ListSnapshotsContext(ctx context.Context, nameFilter string, statuses []string) ([]SnapshotResponse, error) {
+	result := []SnapshotResponse{}
+
+	queryParameters := map[string]interface{}{
+		"api-version": snapshotsAPIVersion,
+	}
+	if nameFilter != "" {
+		queryParameters["name"] = nameFilter
+	}
+	if len(statuses) > 0 {
+		queryParameters["status"] = strings.Join(statuses, ",")
+	}
+
+	err := client.list(ctx, "/snapshots", queryParameters, nil, func(resp *http.Response) (string, error) {
+		page := snapshotListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		result = append(result, page.Items...)
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListSnapshotKeyValues lists the key-values contained in a snapshot, across all pages
+func (client *Client) ListSnapshotKeyValues(name string) ([]KeyValueResponse, error) {
+	return client.ListSnapshotKeyValuesContext(context.Background(), name)
+}
+
+// ListSnapshotKeyValuesContext is ListSnapshotKeyValues, the requests being canceled with the context
+func (client *Client) ListSnapshotKeyValuesContext(ctx context.Context, name string) ([]KeyValueResponse, error) {
+	result := []KeyValueResponse{}
+
+	queryParameters := map[string]interface{}{
+		"api-version": snapshotsAPIVersion,
+		"snapshot":    name,
+	}
+
+	err := client.list(ctx, "/kv", queryParameters, nil, func(resp *http.Response) (string, error) {
+		page := keyValueListPayload{}
+		if err := getJSON(resp, &page); err != nil {
+			return "", err
+		}
+
+		result = append(result, page.Items...)
+
+		return page.NextLink, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ArchiveSnapshot archives a ready snapshot, which then expires at the end of its retention period
+func (client *Client) ArchiveSnapshot(name string, options ...RequestOption) (SnapshotResponse, error) {
+	return client.ArchiveSnapshotContext(context.Background(), name, options...)
+}
+
+// ArchiveSnapshotContext is ArchiveSnapshot, the requests being canceled with the context
+func (client *Client) ArchiveSnapshotContext(ctx context.Context, name string, options ...RequestOption) (SnapshotResponse, error) {
+	return client.updateSnapshotStatus(ctx, name, SnapshotStatusArchived, options...)
+}
+
+// RecoverSnapshot makes an archived snapshot ready again
+func (client *Client) RecoverSnapshot(name string, options ...RequestOption) (SnapshotResponse, error) {
+	return client.RecoverSnapshotContext(context.Background(), name, options...)
+}
+
+// RecoverSnapshotContext is RecoverSnapshot, the requests being canceled with the context
+func (client *Client) RecoverSnapshotContext(ctx context.Context, name string, options ...RequestOption) (SnapshotResponse, error) {
+	return client.updateSnapshotStatus(ctx, name, SnapshotStatusReady, options...)
+}
+
+func (client *Client) updateSnapshotStatus(ctx context.Context, name string, status string, options ...RequestOption) (SnapshotResponse, error) {
+	result := SnapshotResponse{}
+	resp, err := client.sendPrepared(ctx, client.getSnapshotPreparer(
+		name,
+		autorest.AsContentType("application/merge-patch+json"),
+		autorest.AsPatch(),
+		autorest.WithJSON(updateSnapshotPayload{Status: status}),
+		withOptions(options),
+	))
+	if err != nil {
+		return result, err
+	}
+
+	if err = getJSON(resp, &result); err != nil {
+		return result, UnexpectedError.wrap(err)
+	}
+
+	result.ETag = etagOf(resp, result.ETag)
+
+	return result, nil
+}
+
+// waitForOperation polls a long-running operation until it is over, or until the timeout expires or the context is done
+func (client *Client) waitForOperation(ctx context.Context, operation string, timeout time.Duration) error {
+	operationURL, err := client.resolve(operation)
+	if err != nil {
+		return UnexpectedError.wrap(err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := client.sendPrepared(ctx, autorest.CreatePreparer(
+			autorest.WithBaseURL(operationURL),
+			autorest.AsGet(),
+		))
+		if err != nil {
+			return err
+		}
+
+		delay := retryAfter(resp, snapshotPollingDelay)
+
+		result := operationPayload{}
+		if err = getJSON(resp, &result); err != nil {
+			return UnexpectedError.wrap(err)
+		}
+
+		switch result.Status {
+		case "Succeeded":
+			return nil
+		case "Failed", "Canceled":
+			return SnapshotFailedError.with(fmt.Sprintf("%s: %s", result.Error.Code, result.Error.Message))
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return UnexpectedError.with(fmt.Sprintf("timeout while waiting for the operation %s", operation))
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return UnexpectedError.wrap(ctx.Err())
+		}
+	}
+}
+
+func (client *Client) getSnapshotPreparer(name string, additionalDecorators ...autorest.PrepareDecorator) autorest.Preparer {
+	queryParameters := map[string]interface{}{
+		"api-version": snapshotsAPIVersion,
+	}
+
+	pathParameters := map[string]interface{}{
+		"name": escapePathSegment(name),
+	}
+
+	decorators := []autorest.PrepareDecorator{
+		autorest.WithBaseURL(client.Endpoint),
+		autorest.WithPathParameters("/snapshots/{name}", pathParameters),
+		withQueryParameters(queryParameters),
+	}
+
+	decorators = append(decorators, additionalDecorators...)
+
+	return autorest.CreatePreparer(decorators...)
+}
+
+// retryAfter reads the delay the service asks to wait before the next request
+func retryAfter(resp *http.Response, defaultDelay time.Duration) time.Duration {
+	if delay := parseRetryAfter(resp.Header); delay > 0 {
+		return delay
+	}
+
+	return defaultDelay
+}
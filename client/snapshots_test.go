@@ -0,0 +1,138 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSnapshotsTestSuite(t *testing.T) {
+	suiteTester := new(snapshotsTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type snapshotsTestSuite struct {
+	suite.Suite
+	uri    string
+	prefix string
+	label  string
+	name   string
+	keys   []string
+	client *Client
+}
+
+func (s *snapshotsTestSuite) SetupSuite() {
+	s.uri = testEndpoint
+	client, err := newTestClient(s.uri)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.client = client
+}
+
+func (s *snapshotsTestSuite) SetupTest() {
+	s.prefix = fmt.Sprintf("%s:", uuid.New().String())
+	s.label = uuid.New().String()
+	s.name = uuid.New().String()
+	s.keys = []string{s.prefix + "one", s.prefix + "two"}
+
+	for _, key := range s.keys {
+		if _, err := s.client.SetKeyValue(s.label, key, "value"); err != nil {
+			panic(fmt.Sprintf("Cannot create key-value %s", key))
+		}
+	}
+}
+
+func (s *snapshotsTestSuite) TearDownTest() {
+	for _, key := range s.keys {
+		if _, err := s.client.DeleteKeyValue(s.label, key); err != nil {
+			panic(fmt.Sprintf("Cannot delete key-value %s", key))
+		}
+	}
+}
+
+func (s *snapshotsTestSuite) createSnapshot() SnapshotResponse {
+	filters := []SnapshotFilter{{Key: s.prefix + "*", Label: s.label}}
+	snapshot, err := s.client.CreateSnapshot(s.name, filters, SnapshotCompositionKey, 3600, map[string]string{"tag": "value"}, time.Minute)
+
+	require.Nil(s.T(), err)
+
+	return snapshot
+}
+
+func (s *snapshotsTestSuite) TestCreateSnapshotShouldPass() {
+	snapshot := s.createSnapshot()
+
+	assert.Equal(s.T(), s.name, snapshot.Name)
+	assert.Equal(s.T(), SnapshotStatusReady, snapshot.Status)
+	assert.Equal(s.T(), SnapshotCompositionKey, snapshot.CompositionType)
+	assert.Equal(s.T(), 3600, snapshot.RetentionPeriod)
+	assert.Equal(s.T(), len(s.keys), snapshot.ItemsCount)
+	assert.Equal(s.T(), "value", snapshot.Tags["tag"])
+	require.Len(s.T(), snapshot.Filters, 1)
+	assert.Equal(s.T(), s.prefix+"*", snapshot.Filters[0].Key)
+}
+
+func (s *snapshotsTestSuite) TestCreateExistingSnapshotShouldFail() {
+	s.createSnapshot()
+
+	_, err := s.client.CreateSnapshot(s.name, []SnapshotFilter{{Key: "*"}}, SnapshotCompositionKey, 0, nil, time.Minute)
+
+	require.NotNil(s.T(), err)
+	requireClientError(s.T(), err, SnapshotExistsError, s.name)
+}
+
+func (s *snapshotsTestSuite) TestListSnapshotKeyValuesShouldBeImmutable() {
+	s.createSnapshot()
+
+	_, err := s.client.SetKeyValue(s.label, s.keys[0], "newValue")
+	require.Nil(s.T(), err)
+
+	result, err := s.client.ListSnapshotKeyValues(s.name)
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, len(s.keys))
+	for _, kv := range result {
+		assert.Contains(s.T(), s.keys, kv.Key)
+		assert.Equal(s.T(), "value", kv.Value)
+	}
+}
+
+func (s *snapshotsTestSuite) TestListSnapshotsShouldPass() {
+	s.createSnapshot()
+
+	result, err := s.client.ListSnapshots(s.name, []string{SnapshotStatusReady})
+
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), s.name, result[0].Name)
+}
+
+func (s *snapshotsTestSuite) TestArchiveAndRecoverSnapshotShouldPass() {
+	s.createSnapshot()
+
+	snapshot, err := s.client.ArchiveSnapshot(s.name)
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), SnapshotStatusArchived, snapshot.Status)
+	assert.NotEmpty(s.T(), snapshot.Expires)
+
+	snapshot, err = s.client.RecoverSnapshot(s.name, IfMatch(snapshot.ETag))
+
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), SnapshotStatusReady, snapshot.Status)
+}
+
+func (s *snapshotsTestSuite) TestGetNonExistingSnapshotShouldFail() {
+	_, err := s.client.GetSnapshot("idontexist")
+
+	require.NotNil(s.T(), err)
+	assert.True(s.T(), IsNotFound(err))
+}
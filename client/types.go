@@ -1,22 +1,46 @@
-package client
-
-// KeyValueResponse represents a Key Value response
-type KeyValueResponse struct {
-	Key          string
-	Label        string
-	ContentType  string `json:"content_type"`
-	Value        string
-	LastModified string `json:"last_modified"`
-	Tags         map[string]string
-}
-
-// FeatureResponse represents a Key Value response
-type FeatureResponse struct {
-	Key          string
-	Label        string
-	ContentType  string `json:"content_type"`
-	Description  string
-	Enabled      bool
-	LastModified string `json:"last_modified"`
-	Tags         map[string]string
-}
+package client
+
+// KeyValueResponse represents a Key Value response
+type KeyValueResponse struct {
+	Key          string
+	Label        string
+	ContentType  string `json:"content_type"`
+	Value        string
+	LastModified string `json:"last_modified"`
+	Tags         map[string]string
+	ETag         string `json:"etag"`
+	Locked       bool
+}
+
+// FeatureResponse represents a Key Value response holding a feature flag
+type FeatureResponse struct {
+	Key          string
+	Label        string
+	ContentType  string `json:"content_type"`
+	LastModified string `json:"last_modified"`
+	Tags         map[string]string
+	ETag         string `json:"etag"`
+	Locked       bool
+	Feature
+}
+
+// SnapshotFilter selects the key-values a snapshot is made of
+type SnapshotFilter struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"`
+}
+
+// SnapshotResponse represents a Snapshot response
+type SnapshotResponse struct {
+	Name            string
+	Status          string
+	Filters         []SnapshotFilter
+	CompositionType string `json:"composition_type"`
+	Created         string
+	Expires         string
+	RetentionPeriod int `json:"retention_period"`
+	Size            int
+	ItemsCount      int `json:"items_count"`
+	Tags            map[string]string
+	ETag            string `json:"etag"`
+}
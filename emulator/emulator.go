@@ -0,0 +1,256 @@
+// Package emulator is an in-process App Configuration store serving the part of the data plane REST API used by
+// the provider, so that the client and the acceptance tests can run offline.
+package emulator
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultPageSize = 100
+
+// Server is an App Configuration store listening on a local address, its endpoint being URL
+type Server struct {
+	*httptest.Server
+
+	// PageSize is the maximum number of items returned in a page by the list endpoints
+	PageSize int
+
+	mu        sync.Mutex
+	history   []*item
+	current   map[itemKey]*item
+	snapshots map[string]*snapshot
+	failures  []failure
+	drops     int
+}
+
+type failure struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+// NewServer starts an emulator listening over http, it must be closed once done
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// NewTLSServer starts an emulator listening over https, its certificate being only trusted by the http client
+// returned by Client()
+func NewTLSServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewTLSServer(s)
+
+	return s
+}
+
+func newServer() *Server {
+	return &Server{
+		PageSize:  defaultPageSize,
+		current:   map[itemKey]*item{},
+		snapshots: map[string]*snapshot{},
+	}
+}
+
+// Throttle makes the next count requests fail with 429 Too Many Requests, asking to retry after the given delay
+func (s *Server) Throttle(count int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.failures = append(s.failures, failure{statusCode: http.StatusTooManyRequests, retryAfter: retryAfter})
+	}
+}
+
+// Fail makes the next count requests fail with the given status code
+func (s *Server) Fail(count int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.failures = append(s.failures, failure{statusCode: statusCode})
+	}
+}
+
+// DropAfterCommit makes the next count requests be served, their changes being made, but their connection be closed
+// before any response is written, the way a response lost in the network would be
+func (s *Server) DropAfterCommit(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drops += count
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.drops > 0 {
+		s.drops--
+		s.serve(httptest.NewRecorder(), r)
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "the connection cannot be dropped", http.StatusInternalServerError)
+			return
+		}
+
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		conn.Close()
+
+		return
+	}
+
+	s.serve(w, r)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("x-ms-request-id", uuid.New().String())
+	w.Header().Set("x-ms-correlation-request-id", uuid.New().String())
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		writeFailure(w, f)
+		return
+	}
+
+	if r.URL.Query().Get("api-version") == "" {
+		writeProblem(w, http.StatusBadRequest, "invalid-api-version", "The api-version query parameter is required", "")
+		return
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case path == "/kv":
+		s.listKeyValues(w, r)
+	case strings.HasPrefix(path, "/kv/"):
+		s.keyValue(w, r, strings.TrimPrefix(path, "/kv/"))
+	case strings.HasPrefix(path, "/locks/"):
+		s.lock(w, r, strings.TrimPrefix(path, "/locks/"))
+	case path == "/revisions":
+		s.listRevisions(w, r)
+	case path == "/labels":
+		s.listLabels(w, r)
+	case path == "/keys":
+		s.listKeys(w, r)
+	case path == "/snapshots":
+		s.listSnapshots(w, r)
+	case strings.HasPrefix(path, "/snapshots/"):
+		s.snapshot(w, r, strings.TrimPrefix(path, "/snapshots/"))
+	case path == "/operations":
+		s.operation(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newETag returns a random ETag, the way App Configuration does
+func newETag() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func quoteETag(etag string) string {
+	return fmt.Sprintf("\"%s\"", etag)
+}
+
+// asOf reads the Accept-Datetime header, a zero time meaning now
+func asOf(r *http.Request) (time.Time, error) {
+	header := r.Header.Get("Accept-Datetime")
+	if header == "" {
+		return time.Time{}, nil
+	}
+
+	return http.ParseTime(header)
+}
+
+// preconditionFailed checks the If-Match and If-None-Match headers against the current ETag, if any
+func preconditionFailed(r *http.Request, current *item) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if current == nil {
+			return true
+		}
+		if ifMatch != "*" && strings.Trim(ifMatch, "\"") != current.etag {
+			return true
+		}
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && current != nil {
+		if ifNoneMatch == "*" || strings.Trim(ifNoneMatch, "\"") == current.etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeJSON writes the body as JSON, a body which cannot be encoded giving a 500. The errors of the write itself, such as
+// a client gone away, are ignored since there is nobody left to report them to.
+func writeJSON(w http.ResponseWriter, statusCode int, contentType string, etag string, body interface{}) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if etag != "" {
+		w.Header().Set("ETag", quoteETag(etag))
+	}
+	w.WriteHeader(statusCode)
+
+	_, _ = w.Write(append(b, '\n'))
+}
+
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Name   string `json:"name,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Status int    `json:"status"`
+}
+
+// writeProblem writes an application/problem+json error, errorType being the last segment of its type URI
+func writeProblem(w http.ResponseWriter, statusCode int, errorType string, title string, name string) {
+	writeJSON(w, statusCode, "application/problem+json; charset=utf-8", "", problem{
+		Type:   fmt.Sprintf("https://azconfig.io/errors/%s", errorType),
+		Title:  title,
+		Name:   name,
+		Detail: title,
+		Status: statusCode,
+	})
+}
+
+func writeFailure(w http.ResponseWriter, f failure) {
+	if f.statusCode != http.StatusTooManyRequests {
+		writeProblem(w, f.statusCode, strings.ReplaceAll(strings.ToLower(http.StatusText(f.statusCode)), " ", "-"), http.StatusText(f.statusCode), "")
+		return
+	}
+
+	seconds := int((f.retryAfter + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.Header().Set("retry-after-ms", strconv.FormatInt(int64(f.retryAfter/time.Millisecond), 10))
+	writeProblem(w, http.StatusTooManyRequests, "too-many-requests", "Resource utilization has surpassed the assigned quota", "")
+}
@@ -0,0 +1,124 @@
+package emulator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEmulatorTestSuite(t *testing.T) {
+	suiteTester := new(emulatorTestSuite)
+	suite.Run(t, suiteTester)
+}
+
+type emulatorTestSuite struct {
+	suite.Suite
+	server *Server
+}
+
+func (s *emulatorTestSuite) SetupTest() {
+	s.server = NewServer()
+}
+
+func (s *emulatorTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *emulatorTestSuite) do(method string, path string, body string) *http.Response {
+	req, err := http.NewRequest(method, s.server.URL+path, strings.NewReader(body))
+	require.Nil(s.T(), err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(s.T(), err)
+	resp.Body.Close()
+
+	return resp
+}
+
+func (s *emulatorTestSuite) TestMissingAPIVersionShouldFail() {
+	resp := s.do(http.MethodGet, "/kv", "")
+
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+func (s *emulatorTestSuite) TestThrottleShouldAskToRetryLater() {
+	s.server.Throttle(1, 1500*time.Millisecond)
+
+	resp := s.do(http.MethodGet, "/kv?api-version=1.0", "")
+	assert.Equal(s.T(), http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(s.T(), "2", resp.Header.Get("Retry-After"))
+	assert.Equal(s.T(), "1500", resp.Header.Get("retry-after-ms"))
+	assert.NotEmpty(s.T(), resp.Header.Get("x-ms-request-id"))
+
+	resp = s.do(http.MethodGet, "/kv?api-version=1.0", "")
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+}
+
+func (s *emulatorTestSuite) TestFailShouldReturnStatusCode() {
+	s.server.Fail(1, http.StatusServiceUnavailable)
+
+	resp := s.do(http.MethodGet, "/kv?api-version=1.0", "")
+	assert.Equal(s.T(), http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func (s *emulatorTestSuite) TestDropAfterCommitShouldCloseTheConnection() {
+	s.server.DropAfterCommit(1)
+
+	req, err := http.NewRequest(http.MethodPut, s.server.URL+"/kv/myKey?api-version=1.0", strings.NewReader(`{"value":"myValue"}`))
+	require.Nil(s.T(), err)
+	_, err = http.DefaultClient.Do(req)
+	require.NotNil(s.T(), err)
+
+	resp := s.do(http.MethodGet, "/kv/myKey?api-version=1.0", "")
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+}
+
+// brokenWriter is a response whose client went away
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func (s *emulatorTestSuite) TestWriteJSONShouldNotPanic() {
+	assert.NotPanics(s.T(), func() {
+		writeJSON(brokenWriter{httptest.NewRecorder()}, http.StatusOK, "application/json", "", map[string]string{"key": "value"})
+	})
+
+	w := httptest.NewRecorder()
+	writeJSON(w, http.StatusOK, "application/json", "", make(chan int))
+	assert.Equal(s.T(), http.StatusInternalServerError, w.Code)
+}
+
+func (s *emulatorTestSuite) TestDeleteLockedKeyValueShouldConflict() {
+	resp := s.do(http.MethodPut, "/kv/myKey?api-version=1.0", `{"value":"myValue"}`)
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	resp = s.do(http.MethodPut, "/locks/myKey?api-version=1.0", "")
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	resp = s.do(http.MethodDelete, "/kv/myKey?api-version=1.0", "")
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+	assert.Equal(s.T(), "application/problem+json; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+func (s *emulatorTestSuite) TestParseFilter() {
+	assert.True(s.T(), parseFilter("", false).match("anything"))
+	assert.True(s.T(), parseFilter("MyApp:*", false).match("MyApp:Key"))
+	assert.False(s.T(), parseFilter("MyApp:*", false).match("Other:Key"))
+	assert.True(s.T(), parseFilter("one,two", false).match("two"))
+	assert.True(s.T(), parseFilter(`a\,b`, false).match("a,b"))
+	assert.True(s.T(), parseFilter(`a\*`, false).match("a*"))
+	assert.False(s.T(), parseFilter(`a\*`, false).match("ab"))
+	assert.True(s.T(), parseFilter("\x00", true).match(""))
+	assert.False(s.T(), parseFilter("\x00", true).match("Dev"))
+}
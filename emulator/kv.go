@@ -0,0 +1,295 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+const (
+	keyValueContentType    = "application/vnd.microsoft.appconfig.kv+json; charset=utf-8"
+	keyValueSetContentType = "application/vnd.microsoft.appconfig.kvset+json; charset=utf-8"
+	labelSetContentType    = "application/vnd.microsoft.appconfig.labelset+json; charset=utf-8"
+	keySetContentType      = "application/vnd.microsoft.appconfig.keyset+json; charset=utf-8"
+)
+
+type setKeyValuePayload struct {
+	Value       string            `json:"value"`
+	ContentType string            `json:"content_type"`
+	Tags        map[string]string `json:"tags"`
+}
+
+type labelPayload struct {
+	Name *string `json:"name"`
+}
+
+type keyPayload struct {
+	Name string `json:"name"`
+}
+
+// requestedItem reads the key from the escaped path and the label from the query, a missing label meaning no label
+func requestedItem(w http.ResponseWriter, r *http.Request, escapedKey string) (itemKey, bool) {
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil || key == "" {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid key", escapedKey)
+		return itemKey{}, false
+	}
+
+	label := r.URL.Query().Get("label")
+	if label == nullLabel {
+		label = ""
+	}
+
+	return itemKey{key: key, label: label}, true
+}
+
+func (s *Server) keyValue(w http.ResponseWriter, r *http.Request, escapedKey string) {
+	id, ok := requestedItem(w, r, escapedKey)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.getKeyValue(w, r, id)
+	case http.MethodPut:
+		s.setKeyValue(w, r, id)
+	case http.MethodDelete:
+		s.deleteKeyValue(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) getKeyValue(w http.ResponseWriter, r *http.Request, id itemKey) {
+	t, err := asOf(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid Accept-Datetime header", id.key)
+		return
+	}
+
+	current, ok := s.stateAt(t)[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && preconditionFailed(r, current) {
+		w.Header().Set("ETag", quoteETag(current.etag))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if preconditionFailed(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	w.Header().Set("Last-Modified", current.modified.UTC().Format(http.TimeFormat))
+	writeJSON(w, http.StatusOK, keyValueContentType, current.etag, current.toKeyValue())
+}
+
+func (s *Server) setKeyValue(w http.ResponseWriter, r *http.Request, id itemKey) {
+	payload := setKeyValuePayload{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", fmt.Sprintf("Invalid request body: %s", err), id.key)
+		return
+	}
+
+	current := s.current[id]
+	if preconditionFailed(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if current != nil && current.locked {
+		writeLocked(w, id)
+		return
+	}
+
+	updated := s.revise(item{
+		key:         id.key,
+		label:       id.label,
+		contentType: payload.ContentType,
+		value:       payload.Value,
+		tags:        payload.Tags,
+	})
+
+	writeJSON(w, http.StatusOK, keyValueContentType, updated.etag, updated.toKeyValue())
+}
+
+func (s *Server) deleteKeyValue(w http.ResponseWriter, r *http.Request, id itemKey) {
+	current := s.current[id]
+	if preconditionFailed(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if current == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if current.locked {
+		writeLocked(w, id)
+		return
+	}
+
+	deleted := *current
+	s.revise(item{key: id.key, label: id.label, deleted: true})
+
+	writeJSON(w, http.StatusOK, keyValueContentType, deleted.etag, deleted.toKeyValue())
+}
+
+func (s *Server) lock(w http.ResponseWriter, r *http.Request, escapedKey string) {
+	id, ok := requestedItem(w, r, escapedKey)
+	if !ok {
+		return
+	}
+
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	current := s.current[id]
+	if current == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if preconditionFailed(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	revision := *current
+	revision.locked = r.Method == http.MethodPut
+	updated := s.revise(revision)
+
+	writeJSON(w, http.StatusOK, keyValueContentType, updated.etag, updated.toKeyValue())
+}
+
+func (s *Server) listKeyValues(w http.ResponseWriter, r *http.Request) {
+	if name := r.URL.Query().Get("snapshot"); name != "" {
+		s.listSnapshotKeyValues(w, r, name)
+		return
+	}
+
+	t, err := asOf(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid Accept-Datetime header", "")
+		return
+	}
+
+	keys := parseFilter(r.URL.Query().Get("key"), false)
+	labels := parseFilter(r.URL.Query().Get("label"), true)
+
+	items := []interface{}{}
+	for _, i := range sortedItems(s.stateAt(t)) {
+		if keys.match(i.key) && labels.match(i.label) {
+			items = append(items, i.toKeyValue())
+		}
+	}
+
+	s.page(w, r, keyValueSetContentType, items)
+}
+
+// listRevisions lists the revisions of the key-values matching the filters, most recent first
+func (s *Server) listRevisions(w http.ResponseWriter, r *http.Request) {
+	t, err := asOf(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid Accept-Datetime header", "")
+		return
+	}
+
+	keys := parseFilter(r.URL.Query().Get("key"), false)
+	labels := parseFilter(r.URL.Query().Get("label"), true)
+
+	items := []interface{}{}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		revision := s.history[i]
+		if revision.deleted || (!t.IsZero() && revision.modified.After(t)) {
+			continue
+		}
+
+		if keys.match(revision.key) && labels.match(revision.label) {
+			items = append(items, revision.toKeyValue())
+		}
+	}
+
+	s.page(w, r, keyValueSetContentType, items)
+}
+
+// listLabels lists the labels in use matching the name filter, the key-values without label being listed as a null name
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request) {
+	t, err := asOf(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid Accept-Datetime header", "")
+		return
+	}
+
+	names := parseFilter(r.URL.Query().Get("name"), true)
+
+	distinct := map[string]bool{}
+	for _, i := range s.stateAt(t) {
+		if names.match(i.label) {
+			distinct[i.label] = true
+		}
+	}
+
+	labels := []string{}
+	for label := range distinct {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	items := []interface{}{}
+	for _, label := range labels {
+		payload := labelPayload{}
+		if label != "" {
+			name := label
+			payload.Name = &name
+		}
+		items = append(items, payload)
+	}
+
+	s.page(w, r, labelSetContentType, items)
+}
+
+// listKeys lists the distinct keys matching the name filter, whatever their label
+func (s *Server) listKeys(w http.ResponseWriter, r *http.Request) {
+	t, err := asOf(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid Accept-Datetime header", "")
+		return
+	}
+
+	names := parseFilter(r.URL.Query().Get("name"), false)
+
+	distinct := map[string]bool{}
+	for _, i := range s.stateAt(t) {
+		if names.match(i.key) {
+			distinct[i.key] = true
+		}
+	}
+
+	keys := []string{}
+	for key := range distinct {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := []interface{}{}
+	for _, key := range keys {
+		items = append(items, keyPayload{Name: key})
+	}
+
+	s.page(w, r, keySetContentType, items)
+}
+
+func writeLocked(w http.ResponseWriter, id itemKey) {
+	writeProblem(w, http.StatusConflict, "key-locked", fmt.Sprintf("Modifying key '%s' is not allowed", id.key), id.key)
+}
@@ -0,0 +1,250 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotContentType    = "application/vnd.microsoft.appconfig.snapshot+json; charset=utf-8"
+	snapshotSetContentType = "application/vnd.microsoft.appconfig.snapshotset+json; charset=utf-8"
+
+	defaultRetentionPeriod = 30 * 24 * 3600
+)
+
+type snapshotFilter struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"`
+}
+
+type snapshot struct {
+	Name            string            `json:"name"`
+	Status          string            `json:"status"`
+	Filters         []snapshotFilter  `json:"filters"`
+	CompositionType string            `json:"composition_type"`
+	Created         string            `json:"created"`
+	Expires         *string           `json:"expires"`
+	RetentionPeriod int               `json:"retention_period"`
+	Size            int               `json:"size"`
+	ItemsCount      int               `json:"items_count"`
+	Tags            map[string]string `json:"tags"`
+	ETag            string            `json:"etag"`
+
+	items []*item
+}
+
+type createSnapshotPayload struct {
+	Filters         []snapshotFilter  `json:"filters"`
+	CompositionType string            `json:"composition_type"`
+	RetentionPeriod int               `json:"retention_period"`
+	Tags            map[string]string `json:"tags"`
+}
+
+type updateSnapshotPayload struct {
+	Status string `json:"status"`
+}
+
+type operationPayload struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func (s *Server) snapshot(w http.ResponseWriter, r *http.Request, escapedName string) {
+	name, err := url.PathUnescape(escapedName)
+	if err != nil || name == "" {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "Invalid snapshot name", escapedName)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getSnapshot(w, r, name)
+	case http.MethodPut:
+		s.createSnapshot(w, r, name)
+	case http.MethodPatch:
+		s.updateSnapshot(w, r, name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) getSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	snapshot, ok := s.snapshots[name]
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "snapshot-not-found", "Snapshot not found", name)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, snapshotContentType, snapshot.ETag, snapshot)
+}
+
+// createSnapshot composes the snapshot right away, the operation it returns being already over
+func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	if _, ok := s.snapshots[name]; ok {
+		writeProblem(w, http.StatusConflict, "already-exists", fmt.Sprintf("The snapshot '%s' already exists", name), name)
+		return
+	}
+
+	payload := createSnapshotPayload{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", fmt.Sprintf("Invalid request body: %s", err), name)
+		return
+	}
+
+	if len(payload.Filters) < 1 || len(payload.Filters) > 3 {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", "A snapshot requires between 1 and 3 filters", name)
+		return
+	}
+
+	if payload.CompositionType == "" {
+		payload.CompositionType = "key"
+	}
+	if payload.RetentionPeriod == 0 {
+		payload.RetentionPeriod = defaultRetentionPeriod
+	}
+
+	items := s.compose(payload.Filters, payload.CompositionType)
+	size := 0
+	for _, i := range items {
+		size += len(i.key) + len(i.label) + len(i.value) + len(i.contentType)
+	}
+
+	snapshot := &snapshot{
+		Name:            name,
+		Status:          "ready",
+		Filters:         payload.Filters,
+		CompositionType: payload.CompositionType,
+		Created:         time.Now().UTC().Format(time.RFC3339),
+		RetentionPeriod: payload.RetentionPeriod,
+		Size:            size,
+		ItemsCount:      len(items),
+		Tags:            payload.Tags,
+		ETag:            newETag(),
+		items:           items,
+	}
+	if snapshot.Tags == nil {
+		snapshot.Tags = map[string]string{}
+	}
+	s.snapshots[name] = snapshot
+
+	operation := url.URL{Path: "/operations", RawQuery: url.Values{"snapshot": {name}, "api-version": {r.URL.Query().Get("api-version")}}.Encode()}
+	w.Header().Set("Operation-Location", operation.String())
+
+	provisioning := *snapshot
+	provisioning.Status = "provisioning"
+	writeJSON(w, http.StatusCreated, snapshotContentType, snapshot.ETag, provisioning)
+}
+
+// compose selects the current key-values matching the filters. With the key composition, a key found by several
+// filters is taken from the last one.
+func (s *Server) compose(filters []snapshotFilter, compositionType string) []*item {
+	selected := map[itemKey]*item{}
+	for _, f := range filters {
+		keys := parseFilter(f.Key, false)
+		labels := parseFilter(f.Label, true)
+		if f.Label == "" {
+			labels = filter{pattern{}}
+		}
+
+		for _, i := range sortedItems(s.current) {
+			if !keys.match(i.key) || !labels.match(i.label) {
+				continue
+			}
+
+			id := i.id()
+			if compositionType == "key" {
+				id = itemKey{key: i.key}
+			}
+			selected[id] = i
+		}
+	}
+
+	return sortedItems(selected)
+}
+
+func (s *Server) updateSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	snapshot, ok := s.snapshots[name]
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "snapshot-not-found", "Snapshot not found", name)
+		return
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" && strings.Trim(ifMatch, "\"") != snapshot.ETag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	payload := updateSnapshotPayload{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", fmt.Sprintf("Invalid request body: %s", err), name)
+		return
+	}
+
+	switch payload.Status {
+	case "archived":
+		expires := time.Now().Add(time.Duration(snapshot.RetentionPeriod) * time.Second).UTC().Format(time.RFC3339)
+		snapshot.Expires = &expires
+	case "ready":
+		snapshot.Expires = nil
+	default:
+		writeProblem(w, http.StatusBadRequest, "invalid-argument", fmt.Sprintf("Invalid status '%s'", payload.Status), name)
+		return
+	}
+
+	snapshot.Status = payload.Status
+	snapshot.ETag = newETag()
+
+	writeJSON(w, http.StatusOK, snapshotContentType, snapshot.ETag, snapshot)
+}
+
+func (s *Server) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	names := parseFilter(r.URL.Query().Get("name"), false)
+	statuses := parseFilter(r.URL.Query().Get("status"), false)
+
+	sorted := []string{}
+	for name := range s.snapshots {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	items := []interface{}{}
+	for _, name := range sorted {
+		snapshot := s.snapshots[name]
+		if names.match(snapshot.Name) && statuses.match(snapshot.Status) {
+			items = append(items, snapshot)
+		}
+	}
+
+	s.page(w, r, snapshotSetContentType, items)
+}
+
+func (s *Server) listSnapshotKeyValues(w http.ResponseWriter, r *http.Request, name string) {
+	snapshot, ok := s.snapshots[name]
+	if !ok {
+		writeProblem(w, http.StatusNotFound, "snapshot-not-found", "Snapshot not found", name)
+		return
+	}
+
+	items := []interface{}{}
+	for _, i := range snapshot.items {
+		items = append(items, i.toKeyValue())
+	}
+
+	s.page(w, r, keyValueSetContentType, items)
+}
+
+// operation reports the status of a snapshot creation, which is always over
+func (s *Server) operation(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("snapshot")
+	if _, ok := s.snapshots[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, "application/json; charset=utf-8", "", operationPayload{ID: name, Status: "Succeeded"})
+}
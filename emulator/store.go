@@ -0,0 +1,212 @@
+package emulator
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nullLabel is how the label filters designate the key-values without label
+const nullLabel = "\x00"
+
+type itemKey struct {
+	key   string
+	label string
+}
+
+// item is a revision of a key-value, the key-values without label having an empty one
+type item struct {
+	key         string
+	label       string
+	contentType string
+	value       string
+	tags        map[string]string
+	locked      bool
+	etag        string
+	modified    time.Time
+	deleted     bool
+}
+
+type keyValue struct {
+	ETag         string            `json:"etag"`
+	Key          string            `json:"key"`
+	Label        *string           `json:"label"`
+	ContentType  string            `json:"content_type"`
+	Value        string            `json:"value"`
+	Tags         map[string]string `json:"tags"`
+	Locked       bool              `json:"locked"`
+	LastModified string            `json:"last_modified"`
+}
+
+type itemsPayload struct {
+	Items interface{} `json:"items"`
+}
+
+func (i *item) id() itemKey {
+	return itemKey{key: i.key, label: i.label}
+}
+
+func (i *item) toKeyValue() keyValue {
+	var label *string
+	if i.label != "" {
+		label = &i.label
+	}
+
+	tags := i.tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	return keyValue{
+		ETag:         i.etag,
+		Key:          i.key,
+		Label:        label,
+		ContentType:  i.contentType,
+		Value:        i.value,
+		Tags:         tags,
+		Locked:       i.locked,
+		LastModified: i.modified.UTC().Format(time.RFC3339),
+	}
+}
+
+// revise records a new revision of a key-value, which becomes the current one
+func (s *Server) revise(revision item) *item {
+	revision.etag = newETag()
+	revision.modified = time.Now()
+
+	s.history = append(s.history, &revision)
+	if revision.deleted {
+		delete(s.current, revision.id())
+	} else {
+		s.current[revision.id()] = &revision
+	}
+
+	return &revision
+}
+
+// stateAt returns the key-values as they were at the given time, a zero time meaning now
+func (s *Server) stateAt(t time.Time) map[itemKey]*item {
+	if t.IsZero() {
+		return s.current
+	}
+
+	state := map[itemKey]*item{}
+	for _, revision := range s.history {
+		if revision.modified.After(t) {
+			break
+		}
+
+		if revision.deleted {
+			delete(state, revision.id())
+		} else {
+			state[revision.id()] = revision
+		}
+	}
+
+	return state
+}
+
+// sortedItems sorts the items by key then label
+func sortedItems(items map[itemKey]*item) []*item {
+	result := []*item{}
+	for _, i := range items {
+		result = append(result, i)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].key != result[b].key {
+			return result[a].key < result[b].key
+		}
+		return result[a].label < result[b].label
+	})
+
+	return result
+}
+
+// pattern matches a value exactly, or by prefix when the filter ends with a wildcard
+type pattern struct {
+	value  string
+	prefix bool
+}
+
+// filter is a comma-separated list of patterns, as accepted by the key and label filters.
+// A nil filter matches anything.
+type filter []pattern
+
+// parseFilter parses a key or label filter, in which '\' escapes the reserved characters '*', ',' and '\'
+func parseFilter(raw string, isLabel bool) filter {
+	if raw == "" {
+		return nil
+	}
+
+	result := filter{}
+	current := pattern{}
+	escaped := false
+	for _, c := range raw {
+		switch {
+		case escaped:
+			current.value += string(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '*':
+			current.prefix = true
+		case c == ',':
+			result = append(result, current)
+			current = pattern{}
+		default:
+			current.value += string(c)
+		}
+	}
+	result = append(result, current)
+
+	if isLabel {
+		for i := range result {
+			if result[i].value == nullLabel {
+				result[i].value = ""
+			}
+		}
+	}
+
+	return result
+}
+
+func (f filter) match(value string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, p := range f {
+		if p.prefix && strings.HasPrefix(value, p.value) {
+			return true
+		}
+		if !p.prefix && value == p.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// page writes the page of the items selected by the after query parameter, with a Link header to the next one
+func (s *Server) page(w http.ResponseWriter, r *http.Request, contentType string, items []interface{}) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	end := offset + s.PageSize
+	if end < len(items) {
+		query := r.URL.Query()
+		query.Set("after", strconv.Itoa(end))
+		next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		w.Header().Set("Link", "<"+next.String()+">; rel=\"next\"")
+	} else {
+		end = len(items)
+	}
+
+	writeJSON(w, http.StatusOK, contentType, "", itemsPayload{Items: items[offset:end]})
+}
@@ -1,15 +1,15 @@
-package main
-
-import (
-	"github.com/arkiaconsulting/terraform-provider-akc/akc"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
-)
-
-func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return akc.Provider()
-		},
-	})
-}
+package main
+
+import (
+	"github.com/arkiaconsulting/terraform-provider-akc/akc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+)
+
+func main() {
+	plugin.Serve(&plugin.ServeOpts{
+		ProviderFunc: func() *schema.Provider {
+			return akc.Provider()
+		},
+	})
+}